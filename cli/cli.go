@@ -0,0 +1,140 @@
+// Package cli implements the non-interactive subcommands of cimon.
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/Youdontknowme720/Cimonv2/config"
+	"github.com/Youdontknowme720/Cimonv2/gitlab"
+)
+
+// Exit codes of the subcommands.
+const (
+	ExitOK      = 0
+	ExitFailed  = 1
+	ExitUsage   = 2
+	ExitRuntime = 3
+)
+
+const usage = `Usage: cimon [command]
+
+Without a command the interactive monitor is started.
+
+Commands:
+  status [project] [--json] [--limit n]   print the latest pipelines per project
+  wait --project name [--ref ref]         block until the newest pipeline of ref finished
+
+Options:
+  --config path   config file (default: $CIMON_CONFIG or $XDG_CONFIG_HOME/cimon/config.yml)
+`
+
+// ParseGlobalFlags removes the options shared by the monitor and all
+// commands from args and applies them. It returns the remaining arguments.
+func ParseGlobalFlags(args []string) ([]string, error) {
+	rest := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			rest = append(rest, args[i:]...)
+			break
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !strings.HasPrefix(arg, "-") || name != "config" {
+			rest = append(rest, arg)
+			continue
+		}
+		if !hasValue {
+			if i+1 == len(args) {
+				return nil, fmt.Errorf("flag needs an argument: %s", arg)
+			}
+			i++
+			value = args[i]
+		}
+		if value == "" {
+			return nil, fmt.Errorf("empty config path")
+		}
+		config.SetPath(value)
+	}
+	return rest, nil
+}
+
+// IsCommand reports whether args start with a subcommand rather than
+// being empty or starting with a flag.
+func IsCommand(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	switch args[0] {
+	case "-h", "--help":
+		return true
+	}
+	return !strings.HasPrefix(args[0], "-")
+}
+
+// Run executes the subcommand in args and returns the process exit code.
+func Run(args []string) int {
+	switch args[0] {
+	case "status":
+		return runStatus(args[1:])
+	case "wait":
+		return runWait(args[1:])
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+		return ExitOK
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", args[0], usage)
+		return ExitUsage
+	}
+}
+
+// selectProjects returns the configured projects matching query by name or
+// path (case-insensitive) or numeric ID. An empty query selects all projects.
+func selectProjects(projects []config.GitLabProject, query string) []config.GitLabProject {
+	if query == "" {
+		return projects
+	}
+	var selected []config.GitLabProject
+	for _, p := range projects {
+		if strings.EqualFold(p.Name, query) || strings.EqualFold(p.Path, query) || strconv.Itoa(p.ID) == query {
+			selected = append(selected, p)
+		}
+	}
+	return selected
+}
+
+// projectData reads the config. Notices and validation problems are
+// printed to stderr; it returns false if the config could not be read at
+// all.
+func projectData() ([]config.GitLabInstance, []config.GitLabProject, bool) {
+	cfg, err := config.ReadConfig()
+	for _, notice := range cfg.Notices {
+		fmt.Fprintln(os.Stderr, notice)
+	}
+	var validationErr *config.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		fmt.Fprintln(os.Stderr, "warning:", err)
+	case err != nil:
+		fmt.Fprintln(os.Stderr, err)
+		return nil, nil, false
+	}
+	return cfg.Instances, cfg.Projects, true
+}
+
+// clients builds one API client per configured instance. Instances whose
+// token cannot be resolved get a client without token and a warning.
+func clients(instances []config.GitLabInstance) map[string]*gitlab.Client {
+	result := make(map[string]*gitlab.Client, len(instances))
+	for _, inst := range instances {
+		token, err := inst.ResolveToken()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "warning:", err)
+		}
+		result[inst.Name] = gitlab.NewClient(inst.URL, token)
+	}
+	return result
+}
@@ -0,0 +1,181 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Youdontknowme720/Cimonv2/config"
+	"github.com/Youdontknowme720/Cimonv2/gitlab"
+)
+
+type projectStatus struct {
+	Project       string            `json:"project"`
+	ID            int               `json:"id"`
+	Instance      string            `json:"instance"`
+	DefaultBranch string            `json:"default_branch,omitempty"`
+	Latest        *gitlab.Pipeline  `json:"latest_default_branch,omitempty"`
+	Pipelines     []gitlab.Pipeline `json:"pipelines"`
+	Error         string            `json:"error,omitempty"`
+}
+
+// failed reports whether the latest pipeline on the default branch failed.
+func (s projectStatus) failed() bool {
+	return s.Latest != nil && s.Latest.Status == "failed"
+}
+
+func runStatus(args []string) int {
+	flags := flag.NewFlagSet("status", flag.ContinueOnError)
+	asJSON := flags.Bool("json", false, "print JSON instead of a table")
+	limit := flags.Int("limit", 5, "number of pipelines per project")
+	if err := flags.Parse(reorderFlags(args)); err != nil {
+		return ExitUsage
+	}
+	if flags.NArg() > 1 {
+		fmt.Fprintln(os.Stderr, "usage: cimon status [project] [--json] [--limit n]")
+		return ExitUsage
+	}
+	if *limit < 1 {
+		fmt.Fprintln(os.Stderr, "--limit must be at least 1")
+		return ExitUsage
+	}
+
+	instances, projects, ok := projectData()
+	if !ok {
+		return ExitRuntime
+	}
+	selected := selectProjects(projects, flags.Arg(0))
+	if len(selected) == 0 {
+		fmt.Fprintf(os.Stderr, "no project matching %q configured\n", flags.Arg(0))
+		return ExitUsage
+	}
+
+	clientByInstance := clients(instances)
+	statuses := make([]projectStatus, len(selected))
+	for i, proj := range selected {
+		statuses[i] = fetchStatus(clientByInstance[proj.Instance], proj, *limit)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(statuses); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return ExitRuntime
+		}
+	} else {
+		printStatusTable(os.Stdout, statuses)
+	}
+
+	code := ExitOK
+	for _, s := range statuses {
+		switch {
+		case s.failed():
+			code = ExitFailed
+		case s.Error != "" && code == ExitOK:
+			code = ExitRuntime
+		}
+	}
+	return code
+}
+
+func fetchStatus(client *gitlab.Client, proj config.GitLabProject, limit int) projectStatus {
+	status := projectStatus{Project: proj.Name, ID: proj.ID, Instance: proj.Instance, Pipelines: []gitlab.Pipeline{}}
+	if client == nil {
+		status.Error = fmt.Sprintf("instance %q is not configured", proj.Instance)
+		return status
+	}
+	projectID := fmt.Sprint(proj.ID)
+
+	project, err := client.GetProject(projectID)
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	status.DefaultBranch = project.DefaultBranch
+
+	pipelines, err := client.GetAllPipelines(projectID, limit, gitlab.PipelineFilter{})
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	status.Pipelines = pipelines
+
+	for i, p := range pipelines {
+		if p.Ref == project.DefaultBranch {
+			status.Latest = &pipelines[i]
+			return status
+		}
+	}
+	// The default branch may not be among the newest pipelines.
+	if project.DefaultBranch != "" {
+		if latest, err := client.GetLatestPipeline(projectID, project.DefaultBranch); err == nil {
+			status.Latest = latest
+		}
+	}
+	return status
+}
+
+func printStatusTable(out io.Writer, statuses []projectStatus) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROJECT\tINSTANCE\tPIPELINE\tSTATUS\tREF\tCREATED")
+	for _, s := range statuses {
+		if s.Error != "" {
+			fmt.Fprintf(w, "%s\t%s\t-\terror: %s\t\t\n", s.Project, s.Instance, s.Error)
+			continue
+		}
+		if len(s.Pipelines) == 0 {
+			fmt.Fprintf(w, "%s\t%s\t-\tno pipelines\t\t\n", s.Project, s.Instance)
+			continue
+		}
+		listed := false
+		for _, p := range s.Pipelines {
+			fmt.Fprintf(w, "%s\t%s\t#%d\t%s %s\t%s\t%s\n",
+				s.Project, s.Instance, p.ID, gitlab.StatusEmoji(p.Status), p.Status, p.Ref, p.CreatedAt)
+			listed = listed || (s.Latest != nil && p.ID == s.Latest.ID)
+		}
+		if s.Latest != nil && !listed {
+			p := s.Latest
+			fmt.Fprintf(w, "%s\t%s\t#%d\t%s %s\t%s (default)\t%s\n",
+				s.Project, s.Instance, p.ID, gitlab.StatusEmoji(p.Status), p.Status, p.Ref, p.CreatedAt)
+		}
+	}
+	w.Flush()
+}
+
+// reorderFlags moves flags in front of positional arguments, so that
+// "status api --json" works like "status --json api".
+func reorderFlags(args []string) []string {
+	var flags, positional []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			positional = append(positional, args[i+1:]...)
+			return append(flags, positional...)
+		case len(arg) > 1 && arg[0] == '-':
+			flags = append(flags, arg)
+			// Flags given as "--name value" take the next argument along.
+			if !strings.Contains(arg, "=") && takesValue(arg) && i+1 < len(args) {
+				i++
+				flags = append(flags, args[i])
+			}
+		default:
+			positional = append(positional, arg)
+		}
+	}
+	return append(flags, positional...)
+}
+
+// takesValue lists the non-boolean flags of all subcommands.
+func takesValue(flag string) bool {
+	switch flag {
+	case "-limit", "--limit":
+		return true
+	}
+	return false
+}
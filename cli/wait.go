@@ -0,0 +1,207 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/Youdontknowme720/Cimonv2/config"
+	"github.com/Youdontknowme720/Cimonv2/gitlab"
+)
+
+func runWait(args []string) int {
+	flags := flag.NewFlagSet("wait", flag.ContinueOnError)
+	projectName := flags.String("project", "", "configured project name, path or ID")
+	ref := flags.String("ref", "", "branch or tag to wait for (default: the project's default branch)")
+	interval := flags.Duration("interval", 10*time.Second, "polling interval")
+	timeout := flags.Duration("timeout", 0, "give up after this duration (0 waits forever)")
+	if err := flags.Parse(args); err != nil {
+		return ExitUsage
+	}
+	if flags.NArg() > 0 {
+		fmt.Fprintln(os.Stderr, "usage: cimon wait --project name [--ref ref] [--interval 10s] [--timeout 30m]")
+		return ExitUsage
+	}
+
+	instances, projects, ok := projectData()
+	if !ok {
+		return ExitRuntime
+	}
+	proj, ok := singleProject(projects, *projectName)
+	if !ok {
+		return ExitUsage
+	}
+	client, ok := clients(instances)[proj.Instance]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "instance %q is not configured\n", proj.Instance)
+		return ExitUsage
+	}
+
+	w := &waiter{
+		client:    client,
+		projectID: fmt.Sprint(proj.ID),
+		ref:       *ref,
+		jobs:      make(map[int]string),
+		out:       os.Stdout,
+		errOut:    os.Stderr,
+	}
+	if err := w.resolveRef(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return ExitRuntime
+	}
+
+	var deadline <-chan time.Time
+	if *timeout > 0 {
+		deadline = time.After(*timeout)
+	}
+
+	for {
+		if status, done := w.poll(); done {
+			if status == "success" {
+				return ExitOK
+			}
+			return ExitFailed
+		}
+
+		select {
+		case <-deadline:
+			fmt.Fprintf(os.Stderr, "timed out after %s\n", *timeout)
+			return ExitRuntime
+		case <-time.After(*interval):
+		}
+	}
+}
+
+// singleProject picks the project to wait for. Without a name the only
+// configured project is used.
+func singleProject(projects []config.GitLabProject, name string) (config.GitLabProject, bool) {
+	if name == "" {
+		if len(projects) == 1 {
+			return projects[0], true
+		}
+		fmt.Fprintln(os.Stderr, "--project is required when more than one project is configured")
+		return config.GitLabProject{}, false
+	}
+
+	selected := selectProjects(projects, name)
+	switch len(selected) {
+	case 0:
+		fmt.Fprintf(os.Stderr, "no project matching %q configured\n", name)
+		return config.GitLabProject{}, false
+	case 1:
+		return selected[0], true
+	default:
+		fmt.Fprintf(os.Stderr, "%q matches %d projects, use the project ID instead\n", name, len(selected))
+		return config.GitLabProject{}, false
+	}
+}
+
+// waiter follows the newest pipeline of a ref and prints status changes.
+type waiter struct {
+	client    *gitlab.Client
+	projectID string
+	ref       string
+	sha       string
+	pipeline  *gitlab.Pipeline
+	jobs      map[int]string
+	waiting   bool
+	out       io.Writer
+	errOut    io.Writer
+}
+
+// resolveRef fills in the default branch and the commit the pipeline has
+// to belong to. Pipelines of older commits are ignored, so waiting right
+// after a push does not return the result of the previous pipeline.
+func (w *waiter) resolveRef() error {
+	if w.ref == "" {
+		project, err := w.client.GetProject(w.projectID)
+		if err != nil {
+			return err
+		}
+		w.ref = project.DefaultBranch
+	}
+
+	// Tags have no branch; any pipeline for them is accepted.
+	if branch, err := w.client.GetBranch(w.projectID, w.ref); err == nil {
+		w.sha = branch.Commit.ID
+	}
+	return nil
+}
+
+// poll prints what changed since the last call. It returns the pipeline
+// status and true once the pipeline has finished.
+func (w *waiter) poll() (string, bool) {
+	latest, err := w.client.GetLatestPipeline(w.projectID, w.ref)
+	if err != nil && !gitlab.IsNotFound(err) {
+		fmt.Fprintf(w.errOut, "warning: %v\n", err)
+		return "", false
+	}
+	// Not found means the ref has no pipeline yet.
+	if err != nil || !w.follows(latest) {
+		if w.pipeline == nil && !w.waiting {
+			w.log("waiting for a pipeline on %s...", w.describeRef())
+			w.waiting = true
+		}
+		return "", false
+	}
+
+	if w.pipeline == nil || w.pipeline.ID != latest.ID {
+		w.log("pipeline #%d on %s: %s %s  %s", latest.ID, w.ref, gitlab.StatusEmoji(latest.Status), latest.Status, latest.WebURL)
+		w.jobs = make(map[int]string)
+	} else if w.pipeline.Status != latest.Status {
+		w.log("pipeline #%d: %s → %s %s", latest.ID, w.pipeline.Status, gitlab.StatusEmoji(latest.Status), latest.Status)
+	}
+	w.pipeline = latest
+
+	jobs, err := w.client.GetJobDetails(w.projectID, latest.ID)
+	if err != nil {
+		fmt.Fprintf(w.errOut, "warning: %v\n", err)
+	}
+	for _, job := range jobs {
+		previous, seen := w.jobs[job.ID]
+		switch {
+		case !seen:
+			w.log("  %s [%s]: %s %s", job.Name, job.Stage, gitlab.StatusEmoji(job.Status), job.Status)
+		case previous != job.Status:
+			w.log("  %s [%s]: %s → %s %s", job.Name, job.Stage, previous, gitlab.StatusEmoji(job.Status), job.Status)
+		}
+		w.jobs[job.ID] = job.Status
+	}
+
+	if gitlab.IsActiveStatus(latest.Status) {
+		return latest.Status, false
+	}
+	w.log("pipeline #%d finished: %s %s", latest.ID, gitlab.StatusEmoji(latest.Status), latest.Status)
+	return latest.Status, true
+}
+
+// follows reports whether latest is the pipeline to follow: one for the
+// awaited commit, the one already followed, or one for a commit pushed
+// to the branch since, which then becomes the awaited commit.
+func (w *waiter) follows(latest *gitlab.Pipeline) bool {
+	if w.sha == "" || latest.Sha == w.sha {
+		return true
+	}
+	if w.pipeline != nil && w.pipeline.ID == latest.ID {
+		return true
+	}
+	branch, err := w.client.GetBranch(w.projectID, w.ref)
+	if err != nil || branch.Commit.ID != latest.Sha {
+		return false
+	}
+	w.sha = latest.Sha
+	return true
+}
+
+func (w *waiter) describeRef() string {
+	if len(w.sha) >= 8 {
+		return fmt.Sprintf("%s (%s)", w.ref, w.sha[:8])
+	}
+	return w.ref
+}
+
+func (w *waiter) log(format string, args ...any) {
+	fmt.Fprintf(w.out, "[%s] %s\n", time.Now().Format("15:04:05"), fmt.Sprintf(format, args...))
+}
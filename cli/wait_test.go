@@ -0,0 +1,143 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Youdontknowme720/Cimonv2/gitlab"
+)
+
+// waitStep is the state of the server during one poll.
+type waitStep struct {
+	latest *gitlab.Pipeline // nil answers 404
+	code   int              // error status instead of latest
+	head   string           // head commit of the branch
+
+	wantDone   bool
+	wantStatus string
+}
+
+func TestWaiterPoll(t *testing.T) {
+	pipeline := func(id int, sha, status string) *gitlab.Pipeline {
+		return &gitlab.Pipeline{ID: id, Sha: sha, Status: status, Ref: "main"}
+	}
+
+	tests := []struct {
+		name  string
+		sha   string
+		steps []waitStep
+		// wantOut and wantErr must appear in the output, notOut must not.
+		wantOut []string
+		notOut  []string
+		wantErr string
+	}{
+		{
+			name: "no pipeline yet",
+			sha:  "aaa",
+			steps: []waitStep{
+				{head: "aaa"},
+				{head: "aaa"},
+				{latest: pipeline(1, "aaa", "running"), head: "aaa", wantStatus: "running"},
+				{latest: pipeline(1, "aaa", "success"), head: "aaa", wantDone: true, wantStatus: "success"},
+			},
+			wantOut: []string{"waiting for a pipeline on main", "pipeline #1 finished"},
+		},
+		{
+			name: "pipeline of an older commit",
+			sha:  "bbb",
+			steps: []waitStep{
+				{latest: pipeline(1, "aaa", "failed"), head: "bbb"},
+				{latest: pipeline(2, "bbb", "success"), head: "bbb", wantDone: true, wantStatus: "success"},
+			},
+			wantOut: []string{"waiting for a pipeline", "pipeline #2 finished"},
+			notOut:  []string{"pipeline #1"},
+		},
+		{
+			name: "new push mid-wait",
+			sha:  "aaa",
+			steps: []waitStep{
+				{latest: pipeline(1, "aaa", "running"), head: "aaa", wantStatus: "running"},
+				// Pushed, but the new pipeline is not created yet.
+				{latest: pipeline(1, "aaa", "running"), head: "bbb", wantStatus: "running"},
+				{latest: pipeline(2, "bbb", "running"), head: "bbb", wantStatus: "running"},
+				{latest: pipeline(2, "bbb", "failed"), head: "bbb", wantDone: true, wantStatus: "failed"},
+			},
+			wantOut: []string{"pipeline #1 on main", "pipeline #2 on main", "pipeline #2 finished: ❌ failed"},
+			notOut:  []string{"waiting"},
+		},
+		{
+			name: "errors are reported",
+			sha:  "aaa",
+			steps: []waitStep{
+				{code: http.StatusInternalServerError, head: "aaa"},
+				{latest: pipeline(1, "aaa", "success"), head: "aaa", wantDone: true, wantStatus: "success"},
+			},
+			wantErr: "warning:",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var step waitStep
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case strings.HasSuffix(r.URL.Path, "/pipelines/latest"):
+					switch {
+					case step.code != 0:
+						w.WriteHeader(step.code)
+						w.Write([]byte(`{"message":"boom"}`))
+					case step.latest == nil:
+						w.WriteHeader(http.StatusNotFound)
+						w.Write([]byte(`{"message":"404 Not found"}`))
+					default:
+						json.NewEncoder(w).Encode(step.latest)
+					}
+				case strings.HasSuffix(r.URL.Path, "/repository/branches/main"):
+					json.NewEncoder(w).Encode(gitlab.Branch{Name: "main", Commit: gitlab.Commit{ID: step.head}})
+				default:
+					w.Write([]byte(`[]`))
+				}
+			}))
+			defer srv.Close()
+
+			var out, errOut bytes.Buffer
+			w := &waiter{
+				client:    gitlab.NewClient(srv.URL, "token"),
+				projectID: "1",
+				ref:       "main",
+				sha:       tt.sha,
+				jobs:      make(map[int]string),
+				out:       &out,
+				errOut:    &errOut,
+			}
+			for i, s := range tt.steps {
+				step = s
+				status, done := w.poll()
+				if done != s.wantDone || status != s.wantStatus {
+					t.Fatalf("poll %d = %q, %v, want %q, %v\n%s%s", i, status, done, s.wantStatus, s.wantDone, out.String(), errOut.String())
+				}
+			}
+
+			for _, want := range tt.wantOut {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("output does not contain %q:\n%s", want, out.String())
+				}
+			}
+			for _, unwanted := range tt.notOut {
+				if strings.Contains(out.String(), unwanted) {
+					t.Errorf("output contains %q:\n%s", unwanted, out.String())
+				}
+			}
+			if tt.wantErr == "" && errOut.Len() > 0 {
+				t.Errorf("unexpected errors:\n%s", errOut.String())
+			}
+			if tt.wantErr != "" && !strings.Contains(errOut.String(), tt.wantErr) {
+				t.Errorf("errors do not contain %q:\n%s", tt.wantErr, errOut.String())
+			}
+		})
+	}
+}
@@ -3,100 +3,273 @@ package config
 
 import (
 	"fmt"
-	"log"
+	"net/url"
 	"os"
 	"path/filepath"
-
-	"gopkg.in/yaml.v2"
+	"time"
 )
 
+// DefaultURL is the GitLab instance used when an instance has no url.
+const DefaultURL = "https://gitlab.com"
+
 type Config struct {
-	Token    string          `yaml:"token"`
-	Projects []GitLabProject `yaml:"projects"`
+	// URL and Token are the pre-instances single-host settings. They are
+	// migrated into Instances when the config is read.
+	URL       string           `yaml:"url,omitempty"`
+	Token     string           `yaml:"token,omitempty"`
+	Instances []GitLabInstance `yaml:"instances"`
+	Projects  []GitLabProject  `yaml:"projects"`
+
+	// RefreshInterval and ActiveRefreshInterval are the polling intervals
+	// in seconds. The active interval is used while anything is running.
+	RefreshInterval       int `yaml:"refresh_interval,omitempty"`
+	ActiveRefreshInterval int `yaml:"active_refresh_interval,omitempty"`
+
+	// Notices tell the user what ReadConfig did, like creating or
+	// migrating the file. The config package does not print them itself.
+	Notices []string `yaml:"-"`
+}
+
+const (
+	DefaultRefreshInterval       = 30 * time.Second
+	DefaultActiveRefreshInterval = 5 * time.Second
+)
+
+// PollIntervals returns the configured polling intervals, falling back to
+// the defaults for unset values.
+func (c Config) PollIntervals() (idle, active time.Duration) {
+	idle, active = DefaultRefreshInterval, DefaultActiveRefreshInterval
+	if c.RefreshInterval > 0 {
+		idle = time.Duration(c.RefreshInterval) * time.Second
+	}
+	if c.ActiveRefreshInterval > 0 {
+		active = time.Duration(c.ActiveRefreshInterval) * time.Second
+	}
+	return idle, active
+}
+
+type GitLabInstance struct {
+	Name  string `yaml:"name"`
+	URL   string `yaml:"url"`
+	Token string `yaml:"token,omitempty"`
+
+	// TokenEnv, TokenCommand and TokenFile keep the token out of the
+	// config, see ResolveToken.
+	TokenEnv     string `yaml:"token_env,omitempty"`
+	TokenCommand string `yaml:"token_command,omitempty"`
+	TokenFile    string `yaml:"token_file,omitempty"`
 }
 
 type GitLabProject struct {
 	ID   int    `yaml:"id"`
 	Name string `yaml:"name"`
+	// Path is the full namespace path, e.g. group/subgroup/project.
+	Path     string `yaml:"path,omitempty"`
+	Instance string `yaml:"instance"`
 }
 
-func ReadConfig() Config {
-	configDir := "config"
-	configPath := filepath.Join(configDir, "config.yml")
+// Instance returns the instance with the given name.
+func (c Config) Instance(name string) (GitLabInstance, bool) {
+	for _, inst := range c.Instances {
+		if inst.Name == name {
+			return inst, true
+		}
+	}
+	return GitLabInstance{}, false
+}
 
+// ReadConfig reads the config file, creating it if it does not exist. If
+// the file has problems that still allow using it, the config is returned
+// together with a *ValidationError.
+func ReadConfig() (Config, error) {
+	configPath := Path()
+
+	var notices []string
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		return createDefaultConfig(configDir, configPath)
+		migrated, notice := migrateLegacyPath(configPath)
+		if notice != "" {
+			notices = append(notices, notice)
+		}
+		if !migrated {
+			cfg, err := createDefaultConfig(filepath.Dir(configPath), configPath)
+			cfg.Notices = append(notices, cfg.Notices...)
+			return cfg, err
+		}
 	}
-	data, err := os.ReadFile("config/config.yml")
+	data, err := os.ReadFile(configPath)
 	if err != nil {
-		log.Fatal("Error during reading config.yml")
+		return Config{Notices: notices}, fmt.Errorf("couldn't read config: %w", err)
 	}
-	var cfg Config
-	err = yaml.Unmarshal(data, &cfg)
-	if err != nil {
-		log.Fatal("Couldn't read data")
+	cfg, err := parse(configPath, data)
+	cfg.Notices = notices
+	return cfg, err
+}
+
+// migrateLegacyInstance turns the old top-level url/token pair into a named
+// instance and assigns it to every project that has no instance yet.
+func migrateLegacyInstance(cfg *Config) {
+	if cfg.URL != "" || cfg.Token != "" || (len(cfg.Instances) == 0 && len(cfg.Projects) > 0) {
+		legacy := GitLabInstance{
+			Name:  InstanceNameFromURL(cfg.URL),
+			URL:   cfg.URL,
+			Token: cfg.Token,
+		}
+		if legacy.URL == "" {
+			legacy.URL = DefaultURL
+		}
+		if _, exists := cfg.Instance(legacy.Name); !exists {
+			cfg.Instances = append(cfg.Instances, legacy)
+		}
+		cfg.URL = ""
+		cfg.Token = ""
+	}
+
+	for i := range cfg.Projects {
+		if cfg.Projects[i].Instance == "" && len(cfg.Instances) > 0 {
+			cfg.Projects[i].Instance = cfg.Instances[0].Name
+		}
 	}
-	return cfg
 }
 
-func createDefaultConfig(configDir, configPath string) Config {
-	if err := os.MkdirAll(configDir, 0755); err != nil {
-		log.Printf("Warning: Could not create config directory: %v", err)
+// InstanceNameFromURL derives a default instance name from its host.
+func InstanceNameFromURL(instanceURL string) string {
+	if instanceURL == "" {
+		instanceURL = DefaultURL
+	}
+	u, err := url.Parse(instanceURL)
+	if err != nil || u.Host == "" {
+		return instanceURL
 	}
+	return u.Host
+}
 
+func createDefaultConfig(configDir, configPath string) (Config, error) {
 	defaultConfig := Config{
-		Token:    "",
-		Projects: []GitLabProject{},
+		Instances: []GitLabInstance{},
+		Projects:  []GitLabProject{},
 	}
 
-	defaultYAML := `token: ""
+	if err := os.MkdirAll(configDir, 0700); err != nil {
+		return defaultConfig, fmt.Errorf("couldn't create config directory: %w", err)
+	}
+
+	defaultYAML := `instances:
 projects:
 `
 
-	if err := os.WriteFile(configPath, []byte(defaultYAML), 0644); err != nil {
-		log.Printf("Warning: Could not create default config file: %v", err)
-	} else {
-		log.Printf("Created default config at: %s", configPath)
+	if err := writeFileAtomic(configPath, []byte(defaultYAML)); err != nil {
+		return defaultConfig, fmt.Errorf("couldn't create default config: %w", err)
 	}
-
-	return defaultConfig
+	defaultConfig.Notices = []string{"Created default config at: " + configPath}
+	return defaultConfig, nil
 }
 
-func GetProjectData() (string, []GitLabProject) {
-	var activeProject []GitLabProject
-	cfgData := ReadConfig()
-	activeProject = append(activeProject, cfgData.Projects...)
-	return cfgData.Token, activeProject
+// GetProjectData returns the configured instances and projects. Like
+// ReadConfig it may return data together with a *ValidationError.
+func GetProjectData() ([]GitLabInstance, []GitLabProject, error) {
+	cfgData, err := ReadConfig()
+	return cfgData.Instances, cfgData.Projects, err
 }
 
-func AddNewProject(projectID int, projectName string) {
-	cfgData := ReadConfig()
-	newGitlabProject := GitLabProject{projectID, projectName}
-	cfgData.Projects = append(cfgData.Projects, newGitlabProject)
+// AddNewProject appends project to the config.
+func AddNewProject(project GitLabProject) error {
+	return update(func(cfg *Config) error {
+		cfg.Projects = append(cfg.Projects, project)
+		return nil
+	})
+}
 
-	newData, err := yaml.Marshal(cfgData)
+// AddNewProjects appends projects in one write, skipping those that are
+// already configured for the same instance. It returns how many were added.
+func AddNewProjects(projects []GitLabProject) (int, error) {
+	added := 0
+	err := update(func(cfg *Config) error {
+		added = 0
+		for _, p := range projects {
+			if findProject(cfg.Projects, p.Instance, p.ID) >= 0 {
+				continue
+			}
+			cfg.Projects = append(cfg.Projects, p)
+			added++
+		}
+		if added == 0 {
+			return errUnchanged
+		}
+		return nil
+	})
 	if err != nil {
-		fmt.Println("Couldn't write to config file")
-		panic(err)
+		return 0, err
 	}
+	return added, nil
+}
 
-	err = os.WriteFile("config/config.yml", newData, 0644)
-	if err != nil {
-		panic(err)
-	}
+// RemoveProject deletes the project with the given ID on instance.
+func RemoveProject(instance string, projectID int) error {
+	return update(func(cfg *Config) error {
+		i := findProject(cfg.Projects, instance, projectID)
+		if i < 0 {
+			return fmt.Errorf("project %d on %s not found in config", projectID, instance)
+		}
+		cfg.Projects = append(cfg.Projects[:i], cfg.Projects[i+1:]...)
+		return nil
+	})
 }
 
-func AddNewToken(token string) {
-	cfgData := ReadConfig()
-	cfgData.Token = token
+// UpdateProject replaces the project with the given ID on instance.
+func UpdateProject(instance string, projectID int, project GitLabProject) error {
+	return update(func(cfg *Config) error {
+		i := findProject(cfg.Projects, instance, projectID)
+		if i < 0 {
+			return fmt.Errorf("project %d on %s not found in config", projectID, instance)
+		}
+		cfg.Projects[i] = project
+		return nil
+	})
+}
 
-	newData, err := yaml.Marshal(cfgData)
-	if err != nil {
-		panic(err)
-	}
+// MoveProject swaps the project with its previous (delta < 0) or next
+// (delta > 0) neighbour on the same instance, since projects are listed per
+// instance. Moving past the first or last project does nothing.
+func MoveProject(instance string, projectID int, delta int) error {
+	return update(func(cfg *Config) error {
+		i := findProject(cfg.Projects, instance, projectID)
+		if i < 0 {
+			return fmt.Errorf("project %d on %s not found in config", projectID, instance)
+		}
+		step := 1
+		if delta < 0 {
+			step = -1
+		}
+		for j := i + step; j >= 0 && j < len(cfg.Projects); j += step {
+			if cfg.Projects[j].Instance == instance {
+				cfg.Projects[i], cfg.Projects[j] = cfg.Projects[j], cfg.Projects[i]
+				return nil
+			}
+		}
+		return errUnchanged
+	})
+}
 
-	err = os.WriteFile("config/config.yml", newData, 0644)
-	if err != nil {
-		panic(err)
+func findProject(projects []GitLabProject, instance string, projectID int) int {
+	for i, p := range projects {
+		if p.Instance == instance && p.ID == projectID {
+			return i
+		}
 	}
+	return -1
+}
+
+// AddNewToken stores url and token source of the named instance, creating
+// the instance if it does not exist yet.
+func AddNewToken(instance GitLabInstance) error {
+	return update(func(cfg *Config) error {
+		for i := range cfg.Instances {
+			if cfg.Instances[i].Name == instance.Name {
+				cfg.Instances[i] = instance
+				return nil
+			}
+		}
+		cfg.Instances = append(cfg.Instances, instance)
+		return nil
+	})
 }
@@ -0,0 +1,11 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd || windows)
+
+package config
+
+import "os"
+
+// Platforms without flock only get the atomic rename, not the lock.
+
+func lockFile(*os.File) error { return nil }
+
+func unlockFile(*os.File) error { return nil }
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// EnvPath is the environment variable that overrides the config location.
+const EnvPath = "CIMON_CONFIG"
+
+// legacyPath is where Cimon used to keep its config, relative to the
+// working directory.
+const legacyPath = "config/config.yml"
+
+var explicitPath string
+
+// SetPath makes Path return path, e.g. for the --config flag. An empty path
+// restores the default lookup.
+func SetPath(path string) {
+	explicitPath = path
+}
+
+// Path returns the location of the config file: the path set with SetPath,
+// $CIMON_CONFIG, or $XDG_CONFIG_HOME/cimon/config.yml. Without
+// XDG_CONFIG_HOME the user config directory of the platform is used, which
+// is ~/.config on Linux.
+func Path() string {
+	if explicitPath != "" {
+		return explicitPath
+	}
+	if path := os.Getenv(EnvPath); path != "" {
+		return path
+	}
+	return defaultPath()
+}
+
+func defaultPath() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		var err error
+		if dir, err = os.UserConfigDir(); err != nil {
+			// Neither XDG_CONFIG_HOME nor HOME is set; stay with the old
+			// behaviour rather than failing.
+			return legacyPath
+		}
+	}
+	return filepath.Join(dir, "cimon", "config.yml")
+}
+
+// migrateLegacyPath copies config/config.yml from the working directory to
+// path if path is the default location and does not exist yet. It reports
+// whether a config was migrated and returns a message for the user if
+// anything was tried.
+func migrateLegacyPath(path string) (bool, string) {
+	if path != defaultPath() || path == legacyPath {
+		return false, ""
+	}
+	data, err := os.ReadFile(legacyPath)
+	if err != nil {
+		return false, ""
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return false, fmt.Sprintf("Warning: Could not create config directory: %v", err)
+	}
+	if err := writeFileAtomic(path, data); err != nil {
+		return false, fmt.Sprintf("Warning: Could not migrate %s to %s: %v", legacyPath, path, err)
+	}
+	return true, fmt.Sprintf("Migrated config from %s to %s, the old file can be removed", legacyPath, path)
+}
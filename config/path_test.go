@@ -0,0 +1,41 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadConfigCreatesDefault(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cimon", "config.yml")
+	SetPath(path)
+	t.Cleanup(func() { SetPath("") })
+
+	cfg, err := ReadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Notices) != 1 || !strings.Contains(cfg.Notices[0], path) {
+		t.Errorf("notices = %q, want the created path", cfg.Notices)
+	}
+
+	for file, want := range map[string]os.FileMode{filepath.Dir(path): 0700, path: 0600} {
+		info, err := os.Stat(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := info.Mode().Perm(); got != want {
+			t.Errorf("mode of %s = %04o, want %04o", file, got, want)
+		}
+	}
+
+	// Reading the existing file has nothing to report.
+	cfg, err = ReadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Notices) != 0 {
+		t.Errorf("notices = %q, want none", cfg.Notices)
+	}
+}
@@ -0,0 +1,136 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// EnvToken is the environment variable used for a gitlab.com instance
+// without any configured token. Other hosts never get it implicitly, so a
+// gitlab.com token is not sent to them.
+const EnvToken = "GITLAB_TOKEN"
+
+// tokenCommandTimeout limits how long a token_command may run, e.g. while
+// a password manager waits for its passphrase.
+const tokenCommandTimeout = 30 * time.Second
+
+// Token sources of an instance, see GitLabInstance.TokenSource.
+const (
+	TokenSourceConfig  = "config"
+	TokenSourceEnv     = "env"
+	TokenSourceCommand = "command"
+	TokenSourceFile    = "file"
+)
+
+// TokenSource returns where the token of the instance is read from. The
+// first configured of token_env, token_command, token_file and token wins.
+// Without any of them gitlab.com falls back to EnvToken, other instances
+// use no token.
+func (i GitLabInstance) TokenSource() string {
+	switch {
+	case i.TokenEnv != "":
+		return TokenSourceEnv
+	case i.TokenCommand != "":
+		return TokenSourceCommand
+	case i.TokenFile != "":
+		return TokenSourceFile
+	case i.Token != "":
+		return TokenSourceConfig
+	case InstanceNameFromURL(i.URL) == InstanceNameFromURL(DefaultURL):
+		return TokenSourceEnv
+	}
+	return TokenSourceConfig
+}
+
+// ResolveToken returns the access token of the instance. Tokens from the
+// environment, a command or a file are only kept in memory and never end
+// up in the config file.
+func (i GitLabInstance) ResolveToken() (string, error) {
+	switch i.TokenSource() {
+	case TokenSourceEnv:
+		name := i.TokenEnv
+		if name == "" {
+			name = EnvToken
+		}
+		token := os.Getenv(name)
+		if token == "" && i.TokenEnv != "" {
+			return "", fmt.Errorf("instance %s: environment variable %s is not set", i.Name, name)
+		}
+		return token, nil
+	case TokenSourceCommand:
+		return runTokenCommand(i.Name, i.TokenCommand)
+	case TokenSourceFile:
+		return readTokenFile(i.Name, i.TokenFile)
+	}
+	return i.Token, nil
+}
+
+func runTokenCommand(instance, command string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), tokenCommandTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			err = fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", fmt.Errorf("instance %s: token_command failed: %w", instance, err)
+	}
+	// Password managers like pass print the secret on the first line.
+	token, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+	if token == "" {
+		return "", fmt.Errorf("instance %s: token_command printed no token", instance)
+	}
+	return strings.TrimSpace(token), nil
+}
+
+func readTokenFile(instance, path string) (string, error) {
+	path = expandHome(path)
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("instance %s: %w", instance, err)
+	}
+	// Windows has no POSIX permission bits to check.
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0077 != 0 {
+		return "", fmt.Errorf("instance %s: token_file %s must only be accessible by its owner (chmod 600 %s), mode is %04o",
+			instance, path, path, info.Mode().Perm())
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("instance %s: %w", instance, err)
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("instance %s: token_file %s is empty", instance, path)
+	}
+	return token, nil
+}
+
+// expandHome replaces a leading ~/ with the home directory.
+func expandHome(path string) string {
+	rest, ok := strings.CutPrefix(path, "~/")
+	if !ok {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, rest)
+}
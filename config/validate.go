@@ -0,0 +1,181 @@
+package config
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ValidationError lists the problems found in a config file that could
+// still be loaded. Each problem starts with its line number.
+type ValidationError struct {
+	Path     string
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid config %s:\n%s", e.Path, strings.Join(e.Problems, "\n"))
+}
+
+// parse decodes and validates the config file at path. Syntax errors are
+// returned without a config. For unknown keys, duplicates and missing
+// values the config is returned together with a *ValidationError.
+func parse(path string, data []byte) (Config, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return Config{}, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+
+	var cfg Config
+	var problems []string
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil {
+		var typeErr *yaml.TypeError
+		switch {
+		case errors.Is(err, io.EOF):
+			// Empty file.
+		case errors.As(err, &typeErr):
+			for _, msg := range typeErr.Errors {
+				problems = append(problems, unknownFieldRe.ReplaceAllString(msg, "unknown key $1"))
+			}
+		default:
+			return Config{}, fmt.Errorf("invalid config %s: %w", path, err)
+		}
+	}
+
+	problems = append(problems, validate(cfg, &root)...)
+	migrateLegacyInstance(&cfg)
+	if len(problems) > 0 {
+		slices.SortStableFunc(problems, func(a, b string) int {
+			return cmp.Compare(problemLine(a), problemLine(b))
+		})
+		return cfg, &ValidationError{Path: path, Problems: problems}
+	}
+	return cfg, nil
+}
+
+// unknownFieldRe matches the decoder's message for keys that do not exist.
+var unknownFieldRe = regexp.MustCompile(`field (\S+) not found in type \S+`)
+
+// problemLine returns the line number a problem starts with.
+func problemLine(problem string) int {
+	var line int
+	fmt.Sscanf(problem, "line %d:", &line)
+	return line
+}
+
+// validate checks cfg for problems the YAML decoder does not catch. root is
+// used to look up line numbers.
+func validate(cfg Config, root *yaml.Node) []string {
+	var problems []string
+	report := func(line int, format string, args ...any) {
+		problems = append(problems, fmt.Sprintf("line %d: ", line)+fmt.Sprintf(format, args...))
+	}
+
+	instanceNodes := sequenceItems(root, "instances")
+	instanceLines := make(map[string]int)
+	for i, inst := range cfg.Instances {
+		line := itemLine(instanceNodes, i, "name")
+		switch first, seen := instanceLines[inst.Name]; {
+		case inst.Name == "":
+			report(line, "instance has no name")
+		case seen:
+			report(line, "instance %q is defined twice (first in line %d)", inst.Name, first)
+		default:
+			instanceLines[inst.Name] = line
+		}
+		if inst.URL != "" {
+			if u, err := url.Parse(inst.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				report(itemLine(instanceNodes, i, "url"), "instance %q: url %q is not a http(s) URL", inst.Name, inst.URL)
+			}
+		}
+	}
+
+	projectNodes := sequenceItems(root, "projects")
+	type projectKey struct {
+		instance string
+		id       int
+	}
+	projectLines := make(map[projectKey]int)
+	for i, p := range cfg.Projects {
+		line := itemLine(projectNodes, i, "")
+		if p.ID <= 0 {
+			report(itemLine(projectNodes, i, "id"), "project %q has no valid id", p.Name)
+		} else {
+			key := projectKey{p.Instance, p.ID}
+			if first, seen := projectLines[key]; seen {
+				report(line, "project %d is configured twice (first in line %d)", p.ID, first)
+			} else {
+				projectLines[key] = line
+			}
+		}
+		if strings.TrimSpace(p.Name) == "" {
+			report(itemLine(projectNodes, i, "name"), "project %d has no name", p.ID)
+		}
+	}
+
+	if cfg.RefreshInterval < 0 {
+		report(keyLine(root, "refresh_interval"), "refresh_interval must not be negative")
+	}
+	if cfg.ActiveRefreshInterval < 0 {
+		report(keyLine(root, "active_refresh_interval"), "active_refresh_interval must not be negative")
+	}
+	return problems
+}
+
+// mappingValue returns the value node of key in the mapping node m.
+func mappingValue(m *yaml.Node, key string) *yaml.Node {
+	if m == nil {
+		return nil
+	}
+	if m.Kind == yaml.DocumentNode && len(m.Content) > 0 {
+		m = m.Content[0]
+	}
+	if m.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// sequenceItems returns the items of the top-level sequence key.
+func sequenceItems(root *yaml.Node, key string) []*yaml.Node {
+	seq := mappingValue(root, key)
+	if seq == nil || seq.Kind != yaml.SequenceNode {
+		return nil
+	}
+	return seq.Content
+}
+
+// itemLine returns the line of field in the i-th item, or of the item
+// itself if field is empty or missing.
+func itemLine(items []*yaml.Node, i int, field string) int {
+	if i >= len(items) {
+		return 0
+	}
+	if v := mappingValue(items[i], field); field != "" && v != nil {
+		return v.Line
+	}
+	return items[i].Line
+}
+
+// keyLine returns the line of a top-level key.
+func keyLine(root *yaml.Node, key string) int {
+	if v := mappingValue(root, key); v != nil {
+		return v.Line
+	}
+	return 0
+}
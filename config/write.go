@@ -0,0 +1,261 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// errUnchanged is returned by update functions that did not modify the
+// config, so nothing has to be written.
+var errUnchanged = errors.New("config unchanged")
+
+// update runs a read-modify-write of the config file while holding an
+// advisory lock, so concurrent Cimon processes do not drop each other's
+// changes. Validation problems do not prevent changes, so they can be fixed
+// from the UI. Comments and key order of the file are kept.
+func update(modify func(cfg *Config) error) error {
+	configPath := Path()
+	if _, err := ReadConfig(); err != nil {
+		// Creates the file if needed and refuses to touch broken files.
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) {
+			return err
+		}
+	}
+
+	unlock, err := lock(configPath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// Read again under the lock, another process may have written it.
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return fmt.Errorf("couldn't read config: %w", err)
+	}
+	cfg, err := parse(configPath, data)
+	var validationErr *ValidationError
+	if err != nil && !errors.As(err, &validationErr) {
+		return err
+	}
+
+	if err := modify(&cfg); err != nil {
+		if errors.Is(err, errUnchanged) {
+			return nil
+		}
+		return err
+	}
+
+	out, err := encode(cfg, data)
+	if err != nil {
+		return fmt.Errorf("couldn't encode config: %w", err)
+	}
+	if err := writeFileAtomic(configPath, out); err != nil {
+		return fmt.Errorf("couldn't write config: %w", err)
+	}
+	return nil
+}
+
+// lock takes an exclusive advisory lock on a file next to path. The config
+// file itself cannot be locked because it is replaced on every write.
+func lock(path string) (unlock func(), err error) {
+	f, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't lock config: %w", err)
+	}
+	if err := lockFile(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("couldn't lock config: %w", err)
+	}
+	return func() {
+		unlockFile(f)
+		f.Close()
+	}, nil
+}
+
+// writeFileAtomic writes data to a temporary file in the same directory
+// and renames it over path, so readers and crashes never see a partially
+// written file. Symlinks are followed so dotfile setups keep working.
+func writeFileAtomic(path string, data []byte) error {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	// The config may hold plaintext tokens, so an existing file that is
+	// readable by others is tightened as well.
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// encode marshals cfg and merges it into the document original, so
+// comments and the order of keys written by the user are kept.
+func encode(cfg Config, original []byte) ([]byte, error) {
+	var updated yaml.Node
+	if err := updated.Encode(cfg); err != nil {
+		return nil, err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(original, &doc); err != nil {
+		return nil, err
+	}
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) == 1 {
+		mergeNode(doc.Content[0], &updated, reflect.TypeOf(cfg))
+	} else {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{&updated}}
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// mergeNode turns dst into src while keeping the comments of dst and of
+// all entries that still exist. Mapping keys keep their order, new keys
+// are appended, and sequence items are matched by their identity (see
+// itemKey) so comments move along with reordered projects. t is the Go
+// type src was encoded from; keys of dst that are no field of t are kept.
+func mergeNode(dst, src *yaml.Node, t reflect.Type) {
+	if dst.Kind == yaml.ScalarNode && src.Kind == yaml.ScalarNode && dst.Value == src.Value {
+		// Unchanged, keep the quoting of the user.
+		return
+	}
+	if dst.Tag == "!!null" && src.Kind == yaml.SequenceNode && len(src.Content) == 0 {
+		// An empty "projects:" stays as it is instead of becoming "[]".
+		return
+	}
+	if dst.Kind != src.Kind || dst.Kind == yaml.ScalarNode || dst.Kind == yaml.AliasNode {
+		head, line, foot := dst.HeadComment, dst.LineComment, dst.FootComment
+		*dst = *src
+		dst.HeadComment, dst.LineComment, dst.FootComment = head, line, foot
+		return
+	}
+
+	switch dst.Kind {
+	case yaml.MappingNode:
+		var content []*yaml.Node
+		for i := 0; i+1 < len(dst.Content); i += 2 {
+			key, value := dst.Content[i], dst.Content[i+1]
+			field, known := yamlField(t, key.Value)
+			if v := mappingValue(src, key.Value); v != nil {
+				mergeNode(value, v, field)
+				content = append(content, key, value)
+			} else if !known {
+				content = append(content, key, value)
+			}
+		}
+		for i := 0; i+1 < len(src.Content); i += 2 {
+			if mappingValue(dst, src.Content[i].Value) == nil {
+				content = append(content, src.Content[i], src.Content[i+1])
+			}
+		}
+		dst.Content = content
+
+	case yaml.SequenceNode:
+		used := make([]bool, len(dst.Content))
+		content := make([]*yaml.Node, 0, len(src.Content))
+		for _, item := range src.Content {
+			match := -1
+			for i, old := range dst.Content {
+				if !used[i] && itemKey(old) == itemKey(item) {
+					match = i
+					break
+				}
+			}
+			if match < 0 {
+				content = append(content, item)
+				continue
+			}
+			used[match] = true
+			mergeNode(dst.Content[match], item, elemType(t))
+			content = append(content, dst.Content[match])
+		}
+		dst.Content = content
+		if len(content) > 0 {
+			dst.Style &^= yaml.FlowStyle
+		}
+	}
+}
+
+// yamlField returns the type of the field of struct type t that is
+// encoded as key. Keys of other types count as known.
+func yamlField(t reflect.Type, key string) (reflect.Type, bool) {
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, true
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		if f.IsExported() && name == key {
+			return f.Type, true
+		}
+	}
+	return nil, false
+}
+
+// elemType returns the element type of a slice type t.
+func elemType(t reflect.Type) reflect.Type {
+	if t == nil || t.Kind() != reflect.Slice {
+		return nil
+	}
+	return t.Elem()
+}
+
+// itemKey identifies a sequence item: projects by instance and ID,
+// instances by name.
+func itemKey(n *yaml.Node) string {
+	if n.Kind != yaml.MappingNode {
+		return n.Value
+	}
+	var id, name, instance string
+	if v := mappingValue(n, "id"); v != nil {
+		id = v.Value
+	}
+	if v := mappingValue(n, "name"); v != nil {
+		name = v.Value
+	}
+	if v := mappingValue(n, "instance"); v != nil {
+		instance = v.Value
+	}
+	if id != "" {
+		return instance + "/" + id
+	}
+	return name
+}
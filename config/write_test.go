@@ -0,0 +1,177 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// roundTrip parses original, applies modify and encodes the result.
+func roundTrip(t *testing.T, original string, modify func(cfg *Config)) string {
+	t.Helper()
+	cfg, err := parse("config.yml", []byte(original))
+	var validationErr *ValidationError
+	if err != nil && !errors.As(err, &validationErr) {
+		t.Fatal(err)
+	}
+	modify(&cfg)
+	out, err := encode(cfg, []byte(original))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out)
+}
+
+func TestEncodeMoveKeepsComments(t *testing.T) {
+	original := `# Cimon config
+instances:
+  - name: gitlab.com
+    url: https://gitlab.com
+projects:
+  # the backend
+  - id: 1
+    name: api # main service
+    instance: gitlab.com
+  # the frontend
+  - id: 2
+    name: web
+    instance: gitlab.com
+`
+	want := `# Cimon config
+instances:
+  - name: gitlab.com
+    url: https://gitlab.com
+projects:
+  # the frontend
+  - id: 2
+    name: web
+    instance: gitlab.com
+  # the backend
+  - id: 1
+    name: api # main service
+    instance: gitlab.com
+`
+	got := roundTrip(t, original, func(cfg *Config) {
+		cfg.Projects[0], cfg.Projects[1] = cfg.Projects[1], cfg.Projects[0]
+	})
+	if got != want {
+		t.Errorf("encode =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestEncodeKeepsUnknownKeys(t *testing.T) {
+	original := `refresh_intervall: 10 # typo
+instances:
+  - name: corp
+    url: https://gitlab.example.com
+    token: secret
+    comment: on-prem
+projects:
+  - id: 1
+    name: api
+    instance: corp
+`
+	want := `refresh_intervall: 10 # typo
+instances:
+  - name: corp
+    url: https://gitlab.example.com
+    comment: on-prem
+    token_env: CORP_TOKEN
+projects:
+  - id: 1
+    name: api
+    instance: corp
+`
+	got := roundTrip(t, original, func(cfg *Config) {
+		cfg.Instances[0].Token = ""
+		cfg.Instances[0].TokenEnv = "CORP_TOKEN"
+	})
+	if got != want {
+		t.Errorf("encode =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestLegacyMigrationRoundTrip(t *testing.T) {
+	original := `url: https://gitlab.example.com
+token: secret
+projects:
+  - id: 7
+    name: api
+`
+	cfg, err := parse("config.yml", []byte(original))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantCfg := Config{
+		Instances: []GitLabInstance{{Name: "gitlab.example.com", URL: "https://gitlab.example.com", Token: "secret"}},
+		Projects:  []GitLabProject{{ID: 7, Name: "api", Instance: "gitlab.example.com"}},
+	}
+	if !reflect.DeepEqual(cfg, wantCfg) {
+		t.Fatalf("parse = %+v, want %+v", cfg, wantCfg)
+	}
+
+	out, err := encode(cfg, []byte(original))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantOut := `projects:
+  - id: 7
+    name: api
+    instance: gitlab.example.com
+instances:
+  - name: gitlab.example.com
+    url: https://gitlab.example.com
+    token: secret
+`
+	if string(out) != wantOut {
+		t.Errorf("encode =\n%s\nwant\n%s", out, wantOut)
+	}
+
+	again, err := parse("config.yml", out)
+	if err != nil {
+		t.Fatalf("parse of written config: %v\n%s", err, out)
+	}
+	if !reflect.DeepEqual(again, cfg) {
+		t.Errorf("round trip = %+v, want %+v", again, cfg)
+	}
+}
+
+func TestValidateLineNumbers(t *testing.T) {
+	data := `instances:
+  - name: corp
+    url: ftp://gitlab.example.com
+  - name: corp
+    url: https://gitlab.example.com
+projects:
+  - id: 1
+    name: api
+    instance: corp
+  - id: 1
+    name: api again
+    instance: corp
+  - id: 0
+    name: " "
+    instance: corp
+refresh_interval: -5
+foo: bar
+`
+	_, err := parse("config.yml", []byte(data))
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("parse error = %v, want *ValidationError", err)
+	}
+
+	want := []string{
+		`line 3: instance "corp": url "ftp://gitlab.example.com" is not a http(s) URL`,
+		`line 4: instance "corp" is defined twice (first in line 2)`,
+		`line 10: project 1 is configured twice (first in line 7)`,
+		`line 13: project " " has no valid id`,
+		`line 14: project 0 has no name`,
+		`line 16: refresh_interval must not be negative`,
+		`line 17: unknown key foo`,
+	}
+	if !reflect.DeepEqual(validationErr.Problems, want) {
+		t.Errorf("problems =\n%s\nwant\n%s", strings.Join(validationErr.Problems, "\n"), strings.Join(want, "\n"))
+	}
+}
@@ -0,0 +1,161 @@
+package gitlab
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// DefaultURL is used when no instance URL is configured.
+const DefaultURL = "https://gitlab.com"
+
+const apiPath = "/api/v4"
+
+// Client sends requests to the REST API of a single GitLab instance.
+type Client struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+// NewClient returns a client for the GitLab instance at instanceURL,
+// e.g. "https://gitlab.example.com". An empty URL means gitlab.com.
+func NewClient(instanceURL, token string) *Client {
+	instanceURL = strings.TrimSpace(instanceURL)
+	if instanceURL == "" {
+		instanceURL = DefaultURL
+	}
+	instanceURL = strings.TrimRight(instanceURL, "/")
+	instanceURL = strings.TrimSuffix(instanceURL, apiPath)
+
+	return &Client{
+		baseURL:    instanceURL + apiPath,
+		token:      token,
+		httpClient: http.DefaultClient,
+	}
+}
+
+// URL returns the instance URL without the API path.
+func (c *Client) URL() string {
+	return strings.TrimSuffix(c.baseURL, apiPath)
+}
+
+func (c *Client) newRequest(method, path string, query url.Values) (*http.Request, error) {
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	return c.newRequestURL(method, u)
+}
+
+// newRequestURL builds an authenticated request for an absolute URL, e.g.
+// one taken from a Link header.
+func (c *Client) newRequestURL(method, u string) (*http.Request, error) {
+	req, err := http.NewRequest(method, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("PRIVATE-TOKEN", c.token)
+	return req, nil
+}
+
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		return nil, newAPIError(req, resp)
+	}
+	return resp, nil
+}
+
+// APIError is returned for responses with a non-success status code.
+type APIError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Status     string
+	Body       string
+}
+
+func newAPIError(req *http.Request, resp *http.Response) *APIError {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return &APIError{
+		Method:     req.Method,
+		Path:       req.URL.Path,
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Body:       strings.TrimSpace(string(body)),
+	}
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("GitLab API error (%s %s): %s %s", e.Method, e.Path, e.Status, e.Body)
+}
+
+// IsNotFound reports whether err is a 404 response. GitLab also answers
+// with 404 for projects the token is not allowed to see.
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// IsUnauthorized reports whether err is a 401 response, i.e. the token is
+// missing, invalid or expired.
+func IsUnauthorized(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnauthorized
+}
+
+func (c *Client) getJSON(path string, query url.Values, v any) error {
+	req, err := c.newRequest(http.MethodGet, path, query)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// postJSON sends body as JSON in a POST request (no body if nil) and
+// decodes the response into v.
+func (c *Client) postJSON(path string, body any, v any) error {
+	req, err := c.newRequest(http.MethodPost, path, nil)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(data))
+		req.ContentLength = int64(len(data))
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func projectPath(projectID string) string {
+	return "/projects/" + url.PathEscape(projectID)
+}
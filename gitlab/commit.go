@@ -1,9 +1,7 @@
 package gitlab
 
 import (
-	"encoding/json"
 	"fmt"
-	"net/http"
 	"net/url"
 )
 
@@ -17,29 +15,11 @@ type Commit struct {
 	CommittedAt string `json:"committed_date"`
 }
 
-func GetCommit(projectID, sha, token string) (*Commit, error) {
-	u := fmt.Sprintf("%s/projects/%s/repository/commits/%s",
-		baseURL, url.PathEscape(projectID), url.PathEscape(sha))
-
-	req, err := http.NewRequest("GET", u, nil)
-	if err != nil {
-		return nil, err
-	}
-	req.Header.Set("PRIVATE-TOKEN", token)
-
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("commit request failed: %s", resp.Status)
-	}
-
-	var c Commit
-	if err := json.NewDecoder(resp.Body).Decode(&c); err != nil {
-		return nil, err
+func (c *Client) GetCommit(projectID, sha string) (*Commit, error) {
+	var commit Commit
+	path := projectPath(projectID) + "/repository/commits/" + url.PathEscape(sha)
+	if err := c.getJSON(path, nil, &commit); err != nil {
+		return nil, fmt.Errorf("commit request failed: %w", err)
 	}
-	return &c, nil
+	return &commit, nil
 }
@@ -0,0 +1,101 @@
+package gitlab
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+const graphQLPath = "/api/graphql"
+
+// graphQL runs query against the GraphQL API of the instance and decodes
+// the data of the response into v. Some information, like the needs of
+// jobs, is only available there.
+func (c *Client) graphQL(query string, variables map[string]any, v any) error {
+	body, err := json.Marshal(map[string]any{"query": query, "variables": variables})
+	if err != nil {
+		return err
+	}
+	req, err := c.newRequestURL(http.MethodPost, c.URL()+graphQLPath)
+	if err != nil {
+		return err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.ContentLength = int64(len(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return err
+	}
+	if len(result.Errors) > 0 {
+		var errs []error
+		for _, e := range result.Errors {
+			errs = append(errs, errors.New(e.Message))
+		}
+		return fmt.Errorf("GitLab GraphQL error: %w", errors.Join(errs...))
+	}
+	return json.Unmarshal(result.Data, v)
+}
+
+// queryPipeline queries fields of a pipeline and decodes them into v. The
+// pipeline is looked up through the project, by ID or by full path. params
+// declares additional variables, which are passed in variables.
+func (c *Client) queryPipeline(projectID string, pipelineID int, fields, params string, variables map[string]any, v any) error {
+	if params != "" {
+		params = ", " + params
+	}
+	pipeline := "pipeline(id: $pipeline) { " + fields + " }"
+	query := `query($project: ID!, $pipeline: CiPipelineID!` + params + `) {
+	project(fullPath: $project) { ` + pipeline + ` }
+}`
+	vars := map[string]any{
+		"project":  projectID,
+		"pipeline": fmt.Sprintf("gid://gitlab/Ci::Pipeline/%d", pipelineID),
+	}
+	if _, err := strconv.Atoi(projectID); err == nil {
+		query = `query($project: [ID!], $pipeline: CiPipelineID!` + params + `) {
+	projects(ids: $project) { nodes { ` + pipeline + ` } }
+}`
+		vars["project"] = []string{"gid://gitlab/Project/" + projectID}
+	}
+	for name, value := range variables {
+		vars[name] = value
+	}
+
+	type project struct {
+		Pipeline json.RawMessage `json:"pipeline"`
+	}
+	var data struct {
+		Projects struct {
+			Nodes []project `json:"nodes"`
+		} `json:"projects"`
+		Project *project `json:"project"`
+	}
+	if err := c.graphQL(query, vars, &data); err != nil {
+		return err
+	}
+	found := data.Project
+	if len(data.Projects.Nodes) > 0 {
+		found = &data.Projects.Nodes[0]
+	}
+	if found == nil || len(found.Pipeline) == 0 || string(found.Pipeline) == "null" {
+		return fmt.Errorf("pipeline %d not found", pipelineID)
+	}
+	return json.Unmarshal(found.Pipeline, v)
+}
@@ -1,10 +1,12 @@
 package gitlab
 
 import (
-	"encoding/json"
+	"cmp"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"slices"
 	"strings"
 )
 
@@ -29,62 +31,130 @@ func StatusEmoji(status string) string {
 	}
 }
 
+// IsActiveStatus reports whether a job or pipeline with this status may
+// still change, i.e. has not reached a terminal state.
+func IsActiveStatus(status string) bool {
+	switch status {
+	case "created", "waiting_for_resource", "preparing", "pending", "running", "scheduled":
+		return true
+	default:
+		return false
+	}
+}
+
 type Job struct {
-	ID       int     `json:"id"`
-	Name     string  `json:"name"`
-	Stage    string  `json:"stage"`
-	Status   string  `json:"status"`
-	Duration float64 `json:"duration"`
-	WebURL   string  `json:"web_url"`
+	ID         int     `json:"id"`
+	Name       string  `json:"name"`
+	Stage      string  `json:"stage"`
+	Status     string  `json:"status"`
+	Ref        string  `json:"ref"`
+	Duration   float64 `json:"duration"`
+	WebURL     string  `json:"web_url"`
+	StartedAt  string  `json:"started_at"`
+	FinishedAt string  `json:"finished_at"`
+	Runner     *Runner `json:"runner"`
+
+	// Bridge is set for trigger jobs. DownstreamPipeline is the pipeline
+	// they started, nil until it was created.
+	Bridge             bool                `json:"-"`
+	DownstreamPipeline *DownstreamPipeline `json:"downstream_pipeline"`
+
+	// SchedulingType is "dag" for jobs with needs and "stage" for jobs
+	// that wait for the previous stages. Like Needs it is only set by
+	// AddJobNeeds.
+	SchedulingType string   `json:"-"`
+	Needs          []string `json:"-"`
+}
+
+type Runner struct {
+	ID          int    `json:"id"`
+	Description string `json:"description"`
+}
+
+// DownstreamPipeline is a child or multi-project pipeline started by a
+// trigger job. ProjectID differs from the parent for multi-project
+// pipelines.
+type DownstreamPipeline struct {
+	ID        int    `json:"id"`
+	ProjectID int    `json:"project_id"`
+	Status    string `json:"status"`
+	Ref       string `json:"ref"`
+	Sha       string `json:"sha"`
+	WebURL    string `json:"web_url"`
 }
 
 type Jobs []Job
 
-func GetJobDetails(projectID string, pipelineID int, accessToken string) (Jobs, error) {
-	url := fmt.Sprintf("https://gitlab.com/api/v4/projects/%s/pipelines/%d/jobs", projectID, pipelineID)
+// GetJobDetails returns the jobs of a pipeline including its trigger jobs
+// (bridges), newest first like the jobs endpoint.
+func (c *Client) GetJobDetails(projectID string, pipelineID int) (Jobs, error) {
+	query := url.Values{}
+	query.Set("per_page", fmt.Sprint(maxPerPage))
+	path := fmt.Sprintf("%s/pipelines/%d/jobs", projectPath(projectID), pipelineID)
 
-	req, err := http.NewRequest("GET", url, nil)
+	jobs, err := collectAll(newPaginator[Job](c, path, query))
 	if err != nil {
-		return nil, fmt.Errorf("fehler beim Erstellen der Anfrage: %w", err)
+		return nil, fmt.Errorf("fehler beim Laden der Jobs: %w", err)
 	}
 
-	req.Header.Add("PRIVATE-TOKEN", accessToken)
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	// The jobs endpoint leaves out trigger jobs.
+	path = fmt.Sprintf("%s/pipelines/%d/bridges", projectPath(projectID), pipelineID)
+	bridges, err := collectAll(newPaginator[Job](c, path, query))
 	if err != nil {
-		return nil, fmt.Errorf("fehler beim Ausführen der Anfrage: %w", err)
+		return nil, fmt.Errorf("fehler beim Laden der Trigger-Jobs: %w", err)
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("GitLab API Fehler (Status: %d): %s", resp.StatusCode, string(body))
+	for _, bridge := range bridges {
+		bridge.Bridge = true
+		jobs = append(jobs, bridge)
 	}
+	slices.SortStableFunc(jobs, func(a, b Job) int {
+		return cmp.Compare(b.ID, a.ID)
+	})
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("fehler beim Lesen der Response: %w", err)
-	}
+	return jobs, nil
+}
 
-	var jobs []Job
-	if err := json.Unmarshal(body, &jobs); err != nil {
-		return nil, fmt.Errorf("fehler beim Parsen der JSON-Response: %w", err)
+func (c *Client) GetJob(projectID string, jobID int) (*Job, error) {
+	var job Job
+	path := fmt.Sprintf("%s/jobs/%d", projectPath(projectID), jobID)
+	if err := c.getJSON(path, nil, &job); err != nil {
+		return nil, err
 	}
+	return &job, nil
+}
 
-	return jobs, nil
+// RetryJob starts a new run of a job. The returned job is the new one.
+func (c *Client) RetryJob(projectID string, jobID int) (*Job, error) {
+	return c.jobAction(projectID, jobID, "retry")
+}
+
+// CancelJob cancels a running or pending job.
+func (c *Client) CancelJob(projectID string, jobID int) (*Job, error) {
+	return c.jobAction(projectID, jobID, "cancel")
+}
+
+// PlayJob starts a manual job.
+func (c *Client) PlayJob(projectID string, jobID int) (*Job, error) {
+	return c.jobAction(projectID, jobID, "play")
 }
 
-func (job Job) GetJobsLog(projectID string, accessToken string) (string, error) {
-	url := fmt.Sprintf("https://gitlab.com/api/v4/projects/%s/jobs/%d/trace", projectID, job.ID)
-	req, err := http.NewRequest("GET", url, nil)
+func (c *Client) jobAction(projectID string, jobID int, action string) (*Job, error) {
+	var job Job
+	path := fmt.Sprintf("%s/jobs/%d/%s", projectPath(projectID), jobID, action)
+	if err := c.postJSON(path, nil, &job); err != nil {
+		return nil, fmt.Errorf("job %s failed: %w", action, err)
+	}
+	return &job, nil
+}
+
+func (c *Client) GetJobsLog(projectID string, job Job) (string, error) {
+	path := fmt.Sprintf("%s/jobs/%d/trace", projectPath(projectID), job.ID)
+	req, err := c.newRequest(http.MethodGet, path, nil)
 	if err != nil {
 		return "", err
 	}
-	req.Header.Add("PRIVATE-TOKEN", accessToken)
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return "", err
 	}
@@ -99,3 +169,97 @@ func (job Job) GetJobsLog(projectID string, accessToken string) (string, error)
 
 	return buf.String(), nil
 }
+
+// LogTail fetches a job trace incrementally. It remembers how many bytes
+// were read and only requests the new ones with an HTTP Range header.
+type LogTail struct {
+	client    *Client
+	projectID string
+	job       Job
+	offset    int64
+	done      bool
+}
+
+// TailJobLog returns a LogTail starting at the beginning of the trace.
+func (c *Client) TailJobLog(projectID string, job Job) *LogTail {
+	return &LogTail{client: c, projectID: projectID, job: job}
+}
+
+// Job returns the job as of the last Fetch.
+func (t *LogTail) Job() Job {
+	return t.job
+}
+
+// Done reports whether the job has finished and its trace was read completely.
+func (t *LogTail) Done() bool {
+	return t.done
+}
+
+// Fetch returns the part of the trace appended since the previous call.
+// Once the job is in a terminal status and the remaining bytes were read,
+// Done returns true.
+func (t *LogTail) Fetch() (string, error) {
+	if t.done {
+		return "", nil
+	}
+
+	// The status is checked before reading, so everything the job wrote
+	// before it finished is guaranteed to be part of this response.
+	job, err := t.client.GetJob(t.projectID, t.job.ID)
+	if err != nil {
+		return "", err
+	}
+	t.job = *job
+
+	chunk, err := t.fetchRange()
+	if err != nil {
+		return "", err
+	}
+	if !IsActiveStatus(t.job.Status) {
+		t.done = true
+	}
+	return chunk, nil
+}
+
+func (t *LogTail) fetchRange() (string, error) {
+	path := fmt.Sprintf("%s/jobs/%d/trace", projectPath(t.projectID), t.job.ID)
+	req, err := t.client.newRequest(http.MethodGet, path, nil)
+	if err != nil {
+		return "", err
+	}
+	if t.offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", t.offset))
+	}
+
+	resp, err := t.client.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusRequestedRangeNotSatisfiable:
+		return "", nil
+	case http.StatusPartialContent:
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return "", err
+		}
+		t.offset += int64(len(data))
+		return string(data), nil
+	case http.StatusOK:
+		// The server ignored the Range header and sent the whole trace.
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return "", err
+		}
+		if int64(len(data)) < t.offset {
+			return "", nil
+		}
+		chunk := data[t.offset:]
+		t.offset = int64(len(data))
+		return string(chunk), nil
+	default:
+		return "", newAPIError(req, resp)
+	}
+}
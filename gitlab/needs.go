@@ -0,0 +1,73 @@
+package gitlab
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const jobNeedsFields = `jobs(after: $after) {
+	pageInfo { hasNextPage endCursor }
+	nodes { id name schedulingType needs { nodes { name } } }
+}`
+
+type jobNeedsPipeline struct {
+	Jobs struct {
+		PageInfo struct {
+			HasNextPage bool   `json:"hasNextPage"`
+			EndCursor   string `json:"endCursor"`
+		} `json:"pageInfo"`
+		Nodes []struct {
+			ID             string `json:"id"`
+			Name           string `json:"name"`
+			SchedulingType string `json:"schedulingType"`
+			Needs          struct {
+				Nodes []struct {
+					Name string `json:"name"`
+				} `json:"nodes"`
+			} `json:"needs"`
+		} `json:"nodes"`
+	} `json:"jobs"`
+}
+
+// AddJobNeeds sets SchedulingType and Needs of the jobs of a pipeline. The
+// REST API does not return needs, so they are read with GraphQL.
+func (c *Client) AddJobNeeds(projectID string, pipelineID int, jobs Jobs) error {
+	byID := make(map[int]*Job, len(jobs))
+	for i := range jobs {
+		byID[jobs[i].ID] = &jobs[i]
+	}
+
+	variables := map[string]any{}
+	for {
+		var pipeline jobNeedsPipeline
+		if err := c.queryPipeline(projectID, pipelineID, jobNeedsFields, "$after: String", variables, &pipeline); err != nil {
+			return fmt.Errorf("fehler beim Laden der Needs: %w", err)
+		}
+
+		jobsPage := pipeline.Jobs
+		for _, node := range jobsPage.Nodes {
+			job, ok := byID[globalIDNumber(node.ID)]
+			if !ok {
+				continue
+			}
+			job.SchedulingType = node.SchedulingType
+			job.Needs = nil
+			for _, need := range node.Needs.Nodes {
+				job.Needs = append(job.Needs, need.Name)
+			}
+		}
+
+		if !jobsPage.PageInfo.HasNextPage {
+			return nil
+		}
+		variables["after"] = jobsPage.PageInfo.EndCursor
+	}
+}
+
+// globalIDNumber returns the numeric ID of a GraphQL global ID like
+// "gid://gitlab/Ci::Build/123".
+func globalIDNumber(gid string) int {
+	id, _ := strconv.Atoi(gid[strings.LastIndex(gid, "/")+1:])
+	return id
+}
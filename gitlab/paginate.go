@@ -0,0 +1,125 @@
+package gitlab
+
+import (
+	"encoding/json"
+	"iter"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// maxPerPage is the largest page size the GitLab API accepts.
+const maxPerPage = 100
+
+// Paginator walks through the pages of a GitLab list endpoint. It follows
+// the Link header if present and falls back to X-Next-Page otherwise.
+type Paginator[T any] struct {
+	client  *Client
+	nextURL string
+}
+
+func newPaginator[T any](c *Client, path string, query url.Values) *Paginator[T] {
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	return &Paginator[T]{client: c, nextURL: u}
+}
+
+// HasNext reports whether another page can be fetched.
+func (p *Paginator[T]) HasNext() bool {
+	return p.nextURL != ""
+}
+
+// Next fetches the next page. It returns nil once all pages have been read.
+func (p *Paginator[T]) Next() ([]T, error) {
+	if !p.HasNext() {
+		return nil, nil
+	}
+
+	req, err := p.client.newRequestURL(http.MethodGet, p.nextURL)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.client.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var items []T
+	if err := json.NewDecoder(resp.Body).Decode(&items); err != nil {
+		return nil, err
+	}
+
+	p.nextURL = nextPageURL(req.URL, resp.Header)
+	return items, nil
+}
+
+// All iterates over every item of every remaining page.
+func (p *Paginator[T]) All() iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for p.HasNext() {
+			items, err := p.Next()
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func collectAll[T any](p *Paginator[T]) ([]T, error) {
+	var all []T
+	for item, err := range p.All() {
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, item)
+	}
+	return all, nil
+}
+
+func nextPageURL(current *url.URL, header http.Header) string {
+	if next := linkNext(header.Get("Link")); next != "" {
+		return next
+	}
+
+	page := header.Get("X-Next-Page")
+	if page == "" {
+		return ""
+	}
+	next := *current
+	query := next.Query()
+	query.Set("page", page)
+	next.RawQuery = query.Encode()
+	return next.String()
+}
+
+// linkNext extracts the rel="next" target of a RFC 8288 Link header.
+func linkNext(link string) string {
+	for _, part := range strings.Split(link, ",") {
+		segments := strings.Split(part, ";")
+		if len(segments) < 2 {
+			continue
+		}
+		target := strings.TrimSpace(segments[0])
+		if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+			continue
+		}
+		for _, param := range segments[1:] {
+			param = strings.ReplaceAll(strings.TrimSpace(param), " ", "")
+			if param == `rel="next"` || param == "rel=next" {
+				return target[1 : len(target)-1]
+			}
+		}
+	}
+	return ""
+}
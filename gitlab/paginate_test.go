@@ -0,0 +1,75 @@
+package gitlab
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestLinkNext(t *testing.T) {
+	tests := []struct {
+		link string
+		want string
+	}{
+		{"", ""},
+		{`<https://gitlab.example.com/api/v4/projects?page=2>; rel="next"`, "https://gitlab.example.com/api/v4/projects?page=2"},
+		{`<https://x/first>; rel="first", <https://x/next>; rel="next", <https://x/last>; rel="last"`, "https://x/next"},
+		{`<https://x/next>; rel=next`, "https://x/next"},
+		{`<https://x/prev>; rel="prev"`, ""},
+		{`https://x/next; rel="next"`, ""},
+	}
+	for _, tt := range tests {
+		if got := linkNext(tt.link); got != tt.want {
+			t.Errorf("linkNext(%q) = %q, want %q", tt.link, got, tt.want)
+		}
+	}
+}
+
+func TestPaginator(t *testing.T) {
+	type item struct {
+		ID int `json:"id"`
+	}
+
+	var requests []string
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.RawQuery)
+		switch r.URL.Query().Get("page") {
+		case "":
+			// Link wins over X-Next-Page, as used by keyset pagination.
+			w.Header().Set("Link", fmt.Sprintf(`<%s/api/v4/items?cursor=abc&page=keyset>; rel="next"`, srv.URL))
+			w.Header().Set("X-Next-Page", "99")
+			fmt.Fprint(w, `[{"id":1},{"id":2}]`)
+		case "keyset":
+			w.Header().Set("X-Next-Page", "3")
+			fmt.Fprint(w, `[{"id":3}]`)
+		case "3":
+			// GitLab sends an empty X-Next-Page on the last page.
+			w.Header().Set("X-Next-Page", "")
+			fmt.Fprint(w, `[{"id":4}]`)
+		default:
+			t.Errorf("unexpected request %s", r.URL)
+			fmt.Fprint(w, `[]`)
+		}
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL, "token")
+	items, err := collectAll(newPaginator[item](client, "/items", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ids []int
+	for _, it := range items {
+		ids = append(ids, it.ID)
+	}
+	if want := []int{1, 2, 3, 4}; !slices.Equal(ids, want) {
+		t.Errorf("ids = %v, want %v", ids, want)
+	}
+	if want := []string{"", "cursor=abc&page=keyset", "cursor=abc&page=3"}; !slices.Equal(requests, want) {
+		t.Errorf("requests = %q, want %q", requests, want)
+	}
+}
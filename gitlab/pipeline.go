@@ -2,9 +2,9 @@
 package gitlab
 
 import (
-	"encoding/json"
 	"fmt"
-	"net/http"
+	"net/url"
+	"time"
 )
 
 type Pipeline struct {
@@ -14,31 +14,153 @@ type Pipeline struct {
 	Sha       string `json:"sha"`
 	WebURL    string `json:"web_url"`
 	CreatedAt string `json:"created_at"`
-}
+	UpdatedAt string `json:"updated_at"`
 
-const baseURL = "https://gitlab.com/api/v4"
+	// StartedAt, FinishedAt and Duration (in seconds) are only returned
+	// for single pipelines, see GetPipeline.
+	StartedAt  string `json:"started_at"`
+	FinishedAt string `json:"finished_at"`
+	Duration   int    `json:"duration"`
+}
 
 type Pipelines []Pipeline
 
-func GetAllPipelines(projectID, token string, perPage int) ([]Pipeline, error) {
-	url := fmt.Sprintf("%s/projects/%s/pipelines?per_page=%d", baseURL, projectID, perPage)
+// PipelineFilter restricts which pipelines are listed. Empty fields are
+// not filtered on.
+type PipelineFilter struct {
+	Ref      string
+	Status   string
+	Source   string
+	Username string
+	// UpdatedAfter lists only pipelines updated after this time.
+	UpdatedAfter time.Time
+}
 
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, err
-	}
-	req.Header.Set("PRIVATE-TOKEN", token)
+// PipelineStatuses are the values the pipeline status filter accepts.
+var PipelineStatuses = []string{
+	"created", "waiting_for_resource", "preparing", "pending", "running",
+	"success", "failed", "canceled", "skipped", "manual", "scheduled",
+}
 
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, err
+// PipelineSources are the common values of the pipeline source filter.
+var PipelineSources = []string{
+	"push", "web", "trigger", "schedule", "api", "external", "pipeline",
+	"chat", "merge_request_event", "external_pull_request_event", "parent_pipeline",
+}
+
+// IsZero reports whether the filter lets all pipelines through.
+func (f PipelineFilter) IsZero() bool {
+	return f == PipelineFilter{}
+}
+
+func (f PipelineFilter) apply(query url.Values) {
+	if f.Ref != "" {
+		query.Set("ref", f.Ref)
 	}
-	defer resp.Body.Close()
+	if f.Status != "" {
+		query.Set("status", f.Status)
+	}
+	if f.Source != "" {
+		query.Set("source", f.Source)
+	}
+	if f.Username != "" {
+		query.Set("username", f.Username)
+	}
+	if !f.UpdatedAfter.IsZero() {
+		query.Set("updated_after", f.UpdatedAfter.UTC().Format(time.RFC3339))
+	}
+}
 
+// PipelineIterator returns a paginator over the pipelines of a project
+// matching filter, newest first, perPage pipelines at a time.
+func (c *Client) PipelineIterator(projectID string, perPage int, filter PipelineFilter) *Paginator[Pipeline] {
+	query := url.Values{}
+	query.Set("per_page", fmt.Sprint(min(perPage, maxPerPage)))
+	filter.apply(query)
+	return newPaginator[Pipeline](c, projectPath(projectID)+"/pipelines", query)
+}
+
+// GetAllPipelines returns up to limit of the newest pipelines of a project
+// matching filter, reading as many pages as needed.
+func (c *Client) GetAllPipelines(projectID string, limit int, filter PipelineFilter) ([]Pipeline, error) {
+	if limit <= 0 {
+		return nil, nil
+	}
 	var pipelines []Pipeline
-	if err := json.NewDecoder(resp.Body).Decode(&pipelines); err != nil {
-		return nil, err
+	for p, err := range c.PipelineIterator(projectID, limit, filter).All() {
+		if err != nil {
+			return nil, err
+		}
+		pipelines = append(pipelines, p)
+		if len(pipelines) >= limit {
+			break
+		}
 	}
 
 	return pipelines, nil
 }
+
+// GetPipeline returns a single pipeline including its timings.
+func (c *Client) GetPipeline(projectID string, pipelineID int) (*Pipeline, error) {
+	var pipeline Pipeline
+	path := fmt.Sprintf("%s/pipelines/%d", projectPath(projectID), pipelineID)
+	if err := c.getJSON(path, nil, &pipeline); err != nil {
+		return nil, fmt.Errorf("pipeline request failed: %w", err)
+	}
+	return &pipeline, nil
+}
+
+// RetryPipeline retries the failed and canceled jobs of a pipeline.
+func (c *Client) RetryPipeline(projectID string, pipelineID int) (*Pipeline, error) {
+	return c.pipelineAction(projectID, pipelineID, "retry")
+}
+
+// CancelPipeline cancels all running and pending jobs of a pipeline.
+func (c *Client) CancelPipeline(projectID string, pipelineID int) (*Pipeline, error) {
+	return c.pipelineAction(projectID, pipelineID, "cancel")
+}
+
+func (c *Client) pipelineAction(projectID string, pipelineID int, action string) (*Pipeline, error) {
+	var pipeline Pipeline
+	path := fmt.Sprintf("%s/pipelines/%d/%s", projectPath(projectID), pipelineID, action)
+	if err := c.postJSON(path, nil, &pipeline); err != nil {
+		return nil, fmt.Errorf("pipeline %s failed: %w", action, err)
+	}
+	return &pipeline, nil
+}
+
+// PipelineVariable is a CI/CD variable passed to a new pipeline.
+type PipelineVariable struct {
+	Key          string `json:"key"`
+	Value        string `json:"value"`
+	VariableType string `json:"variable_type,omitempty"`
+}
+
+// CreatePipeline runs a new pipeline for ref with the given variables.
+func (c *Client) CreatePipeline(projectID, ref string, variables []PipelineVariable) (*Pipeline, error) {
+	body := struct {
+		Ref       string             `json:"ref"`
+		Variables []PipelineVariable `json:"variables,omitempty"`
+	}{ref, variables}
+
+	var pipeline Pipeline
+	if err := c.postJSON(projectPath(projectID)+"/pipeline", body, &pipeline); err != nil {
+		return nil, fmt.Errorf("creating pipeline failed: %w", err)
+	}
+	return &pipeline, nil
+}
+
+// GetLatestPipeline returns the newest pipeline for ref, or for the default
+// branch if ref is empty.
+func (c *Client) GetLatestPipeline(projectID, ref string) (*Pipeline, error) {
+	query := url.Values{}
+	if ref != "" {
+		query.Set("ref", ref)
+	}
+
+	var pipeline Pipeline
+	if err := c.getJSON(projectPath(projectID)+"/pipelines/latest", query, &pipeline); err != nil {
+		return nil, err
+	}
+	return &pipeline, nil
+}
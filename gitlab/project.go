@@ -0,0 +1,84 @@
+package gitlab
+
+import (
+	"fmt"
+	"net/url"
+)
+
+type Project struct {
+	ID                int    `json:"id"`
+	Name              string `json:"name"`
+	PathWithNamespace string `json:"path_with_namespace"`
+	DefaultBranch     string `json:"default_branch"`
+	WebURL            string `json:"web_url"`
+}
+
+type Branch struct {
+	Name    string `json:"name"`
+	Default bool   `json:"default"`
+	Commit  Commit `json:"commit"`
+}
+
+// GetProject looks up a project by numeric ID or by its full path, e.g.
+// "group/subgroup/project".
+func (c *Client) GetProject(projectID string) (*Project, error) {
+	var project Project
+	if err := c.getJSON(projectPath(projectID), nil, &project); err != nil {
+		return nil, fmt.Errorf("project request failed: %w", err)
+	}
+	return &project, nil
+}
+
+// GetBranches returns all branches of a project.
+func (c *Client) GetBranches(projectID string) ([]Branch, error) {
+	query := url.Values{}
+	query.Set("per_page", fmt.Sprint(maxPerPage))
+	return collectAll(newPaginator[Branch](c, projectPath(projectID)+"/repository/branches", query))
+}
+
+// GetBranch returns a single branch including its head commit.
+func (c *Client) GetBranch(projectID, branch string) (*Branch, error) {
+	var b Branch
+	path := projectPath(projectID) + "/repository/branches/" + url.PathEscape(branch)
+	if err := c.getJSON(path, nil, &b); err != nil {
+		return nil, fmt.Errorf("branch request failed: %w", err)
+	}
+	return &b, nil
+}
+
+// GetGroupProjects returns all projects of a group and its subgroups. The
+// group can be given by numeric ID or full path. Archived projects are
+// skipped.
+func (c *Client) GetGroupProjects(groupID string) ([]Project, error) {
+	query := url.Values{}
+	query.Set("include_subgroups", "true")
+	query.Set("archived", "false")
+	query.Set("simple", "true")
+	query.Set("order_by", "path")
+	query.Set("sort", "asc")
+	query.Set("per_page", fmt.Sprint(maxPerPage))
+
+	projects, err := collectAll(newPaginator[Project](c, "/groups/"+url.PathEscape(groupID)+"/projects", query))
+	if err != nil {
+		return nil, fmt.Errorf("group projects request failed: %w", err)
+	}
+	return projects, nil
+}
+
+// SearchProjects returns projects the token's user is a member of whose
+// name or path (including the namespace) contains query.
+func (c *Client) SearchProjects(query string, limit int) ([]Project, error) {
+	values := url.Values{}
+	values.Set("search", query)
+	values.Set("search_namespaces", "true")
+	values.Set("membership", "true")
+	values.Set("simple", "true")
+	values.Set("order_by", "last_activity_at")
+	values.Set("per_page", fmt.Sprint(min(limit, maxPerPage)))
+
+	var projects []Project
+	if err := c.getJSON("/projects", values, &projects); err != nil {
+		return nil, fmt.Errorf("project search failed: %w", err)
+	}
+	return projects, nil
+}
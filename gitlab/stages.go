@@ -0,0 +1,26 @@
+package gitlab
+
+import "fmt"
+
+const stagesFields = `stages { nodes { name } }`
+
+// GetPipelineStages returns the stage names of a pipeline in pipeline
+// order. The REST API has no stage order, so it is read with GraphQL.
+func (c *Client) GetPipelineStages(projectID string, pipelineID int) ([]string, error) {
+	var pipeline struct {
+		Stages struct {
+			Nodes []struct {
+				Name string `json:"name"`
+			} `json:"nodes"`
+		} `json:"stages"`
+	}
+	if err := c.queryPipeline(projectID, pipelineID, stagesFields, "", nil, &pipeline); err != nil {
+		return nil, fmt.Errorf("fehler beim Laden der Stages: %w", err)
+	}
+
+	stages := make([]string, 0, len(pipeline.Stages.Nodes))
+	for _, node := range pipeline.Stages.Nodes {
+		stages = append(stages, node.Name)
+	}
+	return stages, nil
+}
@@ -0,0 +1,339 @@
+package gitlab
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rivo/tview"
+)
+
+// Trace is a parsed job log. ANSI colours are converted into tview colour
+// tags and GitLab's section_start/section_end markers into nested sections.
+type Trace struct {
+	Items []TraceItem
+}
+
+// TraceItem is either a single line or a whole section.
+type TraceItem struct {
+	Line    *TraceLine
+	Section *TraceSection
+}
+
+// TraceLine is one log line, once with tview colour tags and once as plain
+// text for searching.
+type TraceLine struct {
+	Text  string
+	Plain string
+}
+
+// TraceSection is a collapsible part of a trace.
+type TraceSection struct {
+	Name      string
+	Header    TraceLine
+	Collapsed bool
+	Start     time.Time
+	End       time.Time
+	Items     []TraceItem
+}
+
+// Duration returns how long the section ran, or 0 if it has not ended yet.
+func (s *TraceSection) Duration() time.Duration {
+	if s.Start.IsZero() || s.End.IsZero() {
+		return 0
+	}
+	return s.End.Sub(s.Start)
+}
+
+// ParseTrace parses the raw output of the job trace endpoint.
+func ParseTrace(raw string) *Trace {
+	p := &traceParser{trace: &Trace{}}
+	raw = strings.TrimRight(raw, "\n")
+	if raw == "" {
+		return p.trace
+	}
+	for _, line := range strings.Split(raw, "\n") {
+		p.parseLine(strings.TrimSuffix(line, "\r"))
+	}
+	return p.trace
+}
+
+type traceParser struct {
+	trace *Trace
+	stack []*TraceSection
+	style ansiStyle
+}
+
+func (p *traceParser) add(item TraceItem) {
+	if n := len(p.stack); n > 0 {
+		p.stack[n-1].Items = append(p.stack[n-1].Items, item)
+		return
+	}
+	p.trace.Items = append(p.trace.Items, item)
+}
+
+// parseLine handles one physical line. Carriage returns split it into
+// segments that a terminal would draw over each other, so only the last
+// text segment is kept. Section markers always end with a carriage return.
+func (p *traceParser) parseLine(line string) {
+	var (
+		text      string
+		hasText   bool
+		hasMarker bool
+		opened    *TraceSection
+	)
+
+	for _, segment := range strings.Split(line, "\r") {
+		segment = strings.TrimPrefix(segment, "\x1b[0K")
+		switch {
+		case strings.HasPrefix(segment, "section_start:"):
+			opened = p.openSection(strings.TrimPrefix(segment, "section_start:"))
+			hasMarker = true
+		case strings.HasPrefix(segment, "section_end:"):
+			p.closeSection(strings.TrimPrefix(segment, "section_end:"))
+			hasMarker = true
+		case segment != "" || !hasText:
+			text = segment
+			hasText = true
+		}
+	}
+
+	if opened != nil {
+		opened.Header = p.style.convert(text)
+		if opened.Header.Plain == "" {
+			opened.Header = TraceLine{Text: tview.Escape(opened.Name), Plain: opened.Name}
+		}
+		return
+	}
+	if hasText && (text != "" || !hasMarker) {
+		l := p.style.convert(text)
+		p.add(TraceItem{Line: &l})
+	}
+}
+
+// openSection parses "<timestamp>:<name>[options]".
+func (p *traceParser) openSection(marker string) *TraceSection {
+	ts, name, _ := strings.Cut(marker, ":")
+	section := &TraceSection{Name: name, Start: parseUnix(ts)}
+
+	if i := strings.Index(name, "["); i >= 0 && strings.HasSuffix(name, "]") {
+		section.Name = name[:i]
+		for _, opt := range strings.Split(name[i+1:len(name)-1], ",") {
+			if strings.TrimSpace(opt) == "collapsed=true" {
+				section.Collapsed = true
+			}
+		}
+	}
+
+	p.add(TraceItem{Section: section})
+	p.stack = append(p.stack, section)
+	return section
+}
+
+// closeSection ends the named section and any sections left open inside it.
+func (p *traceParser) closeSection(marker string) {
+	ts, name, _ := strings.Cut(marker, ":")
+	for i := len(p.stack) - 1; i >= 0; i-- {
+		if p.stack[i].Name != name {
+			continue
+		}
+		end := parseUnix(ts)
+		for _, s := range p.stack[i:] {
+			s.End = end
+		}
+		p.stack = p.stack[:i]
+		return
+	}
+}
+
+func parseUnix(s string) time.Time {
+	sec, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(sec, 0)
+}
+
+// ansiStyle is the SGR state carried from one line to the next.
+type ansiStyle struct {
+	fg, bg                           string
+	bold, dim, italic, underline, rv bool
+}
+
+func (s ansiStyle) tag() string {
+	fg, bg := s.fg, s.bg
+	if fg == "" {
+		fg = "-"
+	}
+	if bg == "" {
+		bg = "-"
+	}
+	attrs := ""
+	for _, a := range []struct {
+		on   bool
+		flag string
+	}{{s.bold, "b"}, {s.dim, "d"}, {s.italic, "i"}, {s.underline, "u"}, {s.rv, "r"}} {
+		if a.on {
+			attrs += a.flag
+		}
+	}
+	if attrs == "" {
+		attrs = "-"
+	}
+	return fmt.Sprintf("[%s:%s:%s]", fg, bg, attrs)
+}
+
+func (s ansiStyle) isDefault() bool {
+	return s == ansiStyle{}
+}
+
+// convert translates ANSI escape sequences in text into tview tags. Style
+// changes persist in s for the following lines.
+func (s *ansiStyle) convert(text string) TraceLine {
+	var out, plain strings.Builder
+	if !s.isDefault() {
+		out.WriteString(s.tag())
+	}
+
+	for len(text) > 0 {
+		esc := strings.IndexByte(text, '\x1b')
+		if esc < 0 {
+			out.WriteString(tview.Escape(text))
+			plain.WriteString(text)
+			break
+		}
+		out.WriteString(tview.Escape(text[:esc]))
+		plain.WriteString(text[:esc])
+		text = text[esc+1:]
+
+		if !strings.HasPrefix(text, "[") {
+			continue
+		}
+		// CSI: parameters and intermediates up to a final byte in 0x40-0x7e.
+		end := 1
+		for end < len(text) && (text[end] < 0x40 || text[end] > 0x7e) {
+			end++
+		}
+		if end == len(text) {
+			break
+		}
+		if text[end] == 'm' {
+			s.apply(text[1:end])
+			out.WriteString(s.tag())
+		}
+		text = text[end+1:]
+	}
+
+	if !s.isDefault() {
+		out.WriteString("[-:-:-]")
+	}
+	return TraceLine{Text: out.String(), Plain: plain.String()}
+}
+
+// apply applies the SGR parameter list params, e.g. "1;31".
+func (s *ansiStyle) apply(params string) {
+	codes := strings.Split(params, ";")
+	for i := 0; i < len(codes); i++ {
+		code, err := strconv.Atoi(codes[i])
+		if err != nil {
+			code = 0
+		}
+		switch {
+		case code == 0:
+			*s = ansiStyle{}
+		case code == 1:
+			s.bold = true
+		case code == 2:
+			s.dim = true
+		case code == 3:
+			s.italic = true
+		case code == 4:
+			s.underline = true
+		case code == 7:
+			s.rv = true
+		case code == 22:
+			s.bold, s.dim = false, false
+		case code == 23:
+			s.italic = false
+		case code == 24:
+			s.underline = false
+		case code == 27:
+			s.rv = false
+		case code >= 30 && code <= 37:
+			s.fg = ansiPalette[code-30]
+		case code == 39:
+			s.fg = ""
+		case code >= 40 && code <= 47:
+			s.bg = ansiPalette[code-40]
+		case code == 49:
+			s.bg = ""
+		case code >= 90 && code <= 97:
+			s.fg = ansiPalette[code-90+8]
+		case code >= 100 && code <= 107:
+			s.bg = ansiPalette[code-100+8]
+		case code == 38 || code == 48:
+			color, used := extendedColor(codes[i+1:])
+			i += used
+			if code == 38 {
+				s.fg = color
+			} else {
+				s.bg = color
+			}
+		}
+	}
+}
+
+// extendedColor parses the arguments of 38/48, either "5;n" or "2;r;g;b",
+// and returns the colour and the number of consumed parameters.
+func extendedColor(args []string) (string, int) {
+	if len(args) == 0 {
+		return "", 0
+	}
+	nums := make([]int, 0, 4)
+	for _, a := range args {
+		n, _ := strconv.Atoi(a)
+		nums = append(nums, n)
+	}
+
+	switch nums[0] {
+	case 5:
+		if len(nums) < 2 {
+			return "", len(nums)
+		}
+		return color256(nums[1]), 2
+	case 2:
+		if len(nums) < 4 {
+			return "", len(nums)
+		}
+		return fmt.Sprintf("#%02x%02x%02x", nums[1]&0xff, nums[2]&0xff, nums[3]&0xff), 4
+	}
+	return "", 1
+}
+
+// ansiPalette holds the 16 standard terminal colours (xterm defaults).
+var ansiPalette = [16]string{
+	"#000000", "#cd0000", "#00cd00", "#cdcd00", "#0000ee", "#cd00cd", "#00cdcd", "#e5e5e5",
+	"#7f7f7f", "#ff0000", "#00ff00", "#ffff00", "#5c5cff", "#ff00ff", "#00ffff", "#ffffff",
+}
+
+func color256(n int) string {
+	switch {
+	case n < 0 || n > 255:
+		return ""
+	case n < 16:
+		return ansiPalette[n]
+	case n < 232:
+		n -= 16
+		level := func(v int) int {
+			if v == 0 {
+				return 0
+			}
+			return 55 + v*40
+		}
+		return fmt.Sprintf("#%02x%02x%02x", level(n/36), level(n/6%6), level(n%6))
+	default:
+		gray := 8 + (n-232)*10
+		return fmt.Sprintf("#%02x%02x%02x", gray, gray, gray)
+	}
+}
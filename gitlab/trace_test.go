@@ -0,0 +1,221 @@
+package gitlab
+
+import (
+	"testing"
+	"time"
+)
+
+// lines returns the top-level lines of a trace.
+func lines(t *testing.T, trace *Trace) []TraceLine {
+	t.Helper()
+	var result []TraceLine
+	for _, item := range trace.Items {
+		if item.Line == nil {
+			t.Fatalf("unexpected section %q", item.Section.Name)
+		}
+		result = append(result, *item.Line)
+	}
+	return result
+}
+
+func TestParseTraceLines(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want []TraceLine
+	}{
+		{
+			name: "plain",
+			raw:  "hello\nworld\n",
+			want: []TraceLine{{Text: "hello", Plain: "hello"}, {Text: "world", Plain: "world"}},
+		},
+		{
+			name: "colour reset on the same line",
+			raw:  "\x1b[31merror\x1b[0m done",
+			want: []TraceLine{{Text: "[#cd0000:-:-]error[-:-:-] done", Plain: "error done"}},
+		},
+		{
+			name: "style carried across lines",
+			raw:  "\x1b[1;32mgreen\nstill green\x1b[0m\nplain",
+			want: []TraceLine{
+				{Text: "[#00cd00:-:b]green[-:-:-]", Plain: "green"},
+				{Text: "[#00cd00:-:b]still green[-:-:-]", Plain: "still green"},
+				{Text: "plain", Plain: "plain"},
+			},
+		},
+		{
+			name: "bright colours",
+			raw:  "\x1b[91;104mx",
+			want: []TraceLine{{Text: "[#ff0000:#5c5cff:-]x[-:-:-]", Plain: "x"}},
+		},
+		{
+			name: "256 colours",
+			raw:  "\x1b[38;5;196ma\x1b[38;5;244mb\x1b[38;5;9mc\x1b[39m",
+			want: []TraceLine{{Text: "[#ff0000:-:-]a[#808080:-:-]b[#ff0000:-:-]c[-:-:-]", Plain: "abc"}},
+		},
+		{
+			name: "true colour",
+			raw:  "\x1b[38;2;1;2;255;48;2;16;32;48;1mx",
+			want: []TraceLine{{Text: "[#0102ff:#102030:b]x[-:-:-]", Plain: "x"}},
+		},
+		{
+			name: "carriage return overwrites",
+			raw:  "progress 10%\rprogress 50%\rprogress 100%\r\ndone",
+			want: []TraceLine{{Text: "progress 100%", Plain: "progress 100%"}, {Text: "done", Plain: "done"}},
+		},
+		{
+			name: "erase line before text",
+			raw:  "old\r\x1b[0Knew",
+			want: []TraceLine{{Text: "new", Plain: "new"}},
+		},
+		{
+			name: "brackets are escaped",
+			raw:  "[INFO] [red] ok",
+			want: []TraceLine{{Text: "[INFO[] [red[] ok", Plain: "[INFO] [red] ok"}},
+		},
+		{
+			name: "empty lines are kept",
+			raw:  "a\n\nb",
+			want: []TraceLine{{Text: "a", Plain: "a"}, {Text: "", Plain: ""}, {Text: "b", Plain: "b"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := lines(t, ParseTrace(tt.raw))
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d lines %q, want %d", len(got), got, len(tt.want))
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("line %d = %q, want %q", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseTraceSections(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		// want describes the items: lines by their plain text, sections as
+		// "name{...}" with their items.
+		want string
+		// check inspects the first section.
+		check func(t *testing.T, s *TraceSection)
+	}{
+		{
+			name: "section with header",
+			raw: "before\n" +
+				"\x1b[0Ksection_start:100:build\r\x1b[0K\x1b[36mBuilding\x1b[0m\n" +
+				"compile\n" +
+				"\x1b[0Ksection_end:130:build\r\x1b[0K\n" +
+				"after",
+			want: "before build{compile} after",
+			check: func(t *testing.T, s *TraceSection) {
+				if s.Header.Plain != "Building" {
+					t.Errorf("header = %q, want Building", s.Header.Plain)
+				}
+				if s.Duration() != 30*time.Second {
+					t.Errorf("duration = %v, want 30s", s.Duration())
+				}
+			},
+		},
+		{
+			name: "collapsed option",
+			raw: "section_start:1:deps[collapsed=true]\r\x1b[0KDeps\n" +
+				"section_end:2:deps\r\x1b[0K",
+			want: "deps{}",
+			check: func(t *testing.T, s *TraceSection) {
+				if !s.Collapsed {
+					t.Error("section is not collapsed")
+				}
+			},
+		},
+		{
+			name: "header defaults to the name",
+			raw:  "section_start:1:step_script\r\x1b[0K\nrun",
+			want: "step_script{run}",
+			check: func(t *testing.T, s *TraceSection) {
+				if s.Header.Plain != "step_script" {
+					t.Errorf("header = %q, want step_script", s.Header.Plain)
+				}
+			},
+		},
+		{
+			name: "nested",
+			raw: "section_start:1:outer\r\x1b[0KOuter\n" +
+				"a\n" +
+				"section_start:2:inner\r\x1b[0KInner\n" +
+				"b\n" +
+				"section_end:3:inner\r\x1b[0K\n" +
+				"c\n" +
+				"section_end:4:outer\r\x1b[0K\n" +
+				"d",
+			want: "outer{a inner{b} c} d",
+		},
+		{
+			name: "unclosed",
+			raw: "section_start:1:outer\r\x1b[0KOuter\n" +
+				"a\n" +
+				"section_start:2:inner\r\x1b[0KInner\n" +
+				"b",
+			want: "outer{a inner{b}}",
+			check: func(t *testing.T, s *TraceSection) {
+				if s.Duration() != 0 {
+					t.Errorf("duration of running section = %v, want 0", s.Duration())
+				}
+			},
+		},
+		{
+			name: "closing the outer section closes the inner one",
+			raw: "section_start:1:outer\r\x1b[0KOuter\n" +
+				"section_start:2:inner\r\x1b[0KInner\n" +
+				"b\n" +
+				"section_end:5:outer\r\x1b[0K\n" +
+				"c",
+			want: "outer{inner{b}} c",
+			check: func(t *testing.T, s *TraceSection) {
+				inner := s.Items[0].Section
+				if inner.Duration() != 3*time.Second {
+					t.Errorf("inner duration = %v, want 3s", inner.Duration())
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trace := ParseTrace(tt.raw)
+			if got := describeItems(trace.Items); got != tt.want {
+				t.Errorf("items = %s, want %s", got, tt.want)
+			}
+			if tt.check == nil {
+				return
+			}
+			for _, item := range trace.Items {
+				if item.Section != nil {
+					tt.check(t, item.Section)
+					return
+				}
+			}
+			t.Fatal("no section found")
+		})
+	}
+}
+
+func describeItems(items []TraceItem) string {
+	s := ""
+	for i, item := range items {
+		if i > 0 {
+			s += " "
+		}
+		if item.Line != nil {
+			s += item.Line.Plain
+			continue
+		}
+		s += item.Section.Name + "{" + describeItems(item.Section.Items) + "}"
+	}
+	return s
+}
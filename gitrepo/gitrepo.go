@@ -0,0 +1,82 @@
+// Package gitrepo reads the origin remote and the current branch of the git
+// checkout cimon is started in.
+package gitrepo
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"strings"
+)
+
+// Repo describes the git checkout in the working directory.
+type Repo struct {
+	// Host is the host name of the origin remote without port.
+	Host string
+	// Path is the project path on the host, e.g. "group/subgroup/project".
+	Path string
+	// Branch is the checked out branch, empty for a detached HEAD.
+	Branch string
+}
+
+// Detect inspects the git checkout at dir.
+func Detect(dir string) (*Repo, error) {
+	remote, err := gitOutput(dir, "remote", "get-url", "origin")
+	if err != nil {
+		return nil, err
+	}
+	repo, err := ParseRemote(remote)
+	if err != nil {
+		return nil, err
+	}
+
+	if branch, err := gitOutput(dir, "rev-parse", "--abbrev-ref", "HEAD"); err == nil && branch != "HEAD" {
+		repo.Branch = branch
+	}
+	return repo, nil
+}
+
+// ParseRemote splits a remote URL in https, ssh or scp-like
+// ("git@host:group/project.git") form into host and project path.
+func ParseRemote(remote string) (*Repo, error) {
+	remote = strings.TrimSpace(remote)
+	if remote == "" {
+		return nil, errors.New("empty remote URL")
+	}
+
+	var host, path string
+	if strings.Contains(remote, "://") {
+		u, err := url.Parse(remote)
+		if err != nil {
+			return nil, fmt.Errorf("invalid remote URL %q: %w", remote, err)
+		}
+		host, path = u.Hostname(), u.Path
+	} else {
+		// scp-like syntax: [user@]host:path
+		hostPart, pathPart, ok := strings.Cut(remote, ":")
+		if !ok {
+			return nil, fmt.Errorf("unsupported remote URL %q", remote)
+		}
+		if at := strings.LastIndex(hostPart, "@"); at >= 0 {
+			hostPart = hostPart[at+1:]
+		}
+		host, path = hostPart, pathPart
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	if host == "" || path == "" {
+		return nil, fmt.Errorf("unsupported remote URL %q", remote)
+	}
+	return &Repo{Host: host, Path: path}, nil
+}
+
+func gitOutput(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w", strings.Join(args, " "), err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -2,15 +2,30 @@ package main
 
 import (
 	"fmt"
+	"os"
 
+	"github.com/Youdontknowme720/Cimonv2/cli"
 	"github.com/Youdontknowme720/Cimonv2/ui"
 )
 
 func main() {
+	args, err := cli.ParseGlobalFlags(os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(cli.ExitUsage)
+	}
+	if cli.IsCommand(args) {
+		os.Exit(cli.Run(args))
+	}
+	if len(args) > 0 {
+		fmt.Fprintf(os.Stderr, "unknown option %q, see cimon --help\n", args[0])
+		os.Exit(cli.ExitUsage)
+	}
+
 	fmt.Print("Start App")
 	myApp := ui.NewApp()
 	myApp.Setup()
-	err := myApp.Run()
+	err = myApp.Run()
 	if err != nil {
 		return
 	}
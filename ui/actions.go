@@ -0,0 +1,139 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/Youdontknowme720/Cimonv2/gitlab"
+)
+
+// handlePipelineAction retries or cancels the selected pipeline after
+// asking for confirmation and updates its row with the result.
+func (a *App) handlePipelineAction(list *pipelineList, action string) {
+	row, _ := list.table.GetSelection()
+	if row < 1 || row > len(list.pipelines) {
+		a.showNotification("Keine Pipeline ausgewählt", ColorWarning)
+		return
+	}
+	pipeline := list.pipelines[row-1]
+	projectID := fmt.Sprint(list.proj.ID)
+
+	var (
+		label string
+		run   func(projectID string, pipelineID int) (*gitlab.Pipeline, error)
+	)
+	switch action {
+	case "retry":
+		if gitlab.IsActiveStatus(pipeline.Status) {
+			a.showNotification("Pipeline läuft noch – Retry nicht möglich", ColorWarning)
+			return
+		}
+		label, run = "neu starten", list.client.RetryPipeline
+	case "cancel":
+		if !gitlab.IsActiveStatus(pipeline.Status) {
+			a.showNotification("Pipeline läuft nicht – Abbrechen nicht möglich", ColorWarning)
+			return
+		}
+		label, run = "abbrechen", list.client.CancelPipeline
+	default:
+		return
+	}
+
+	a.confirm(fmt.Sprintf("Pipeline #%d (%s) wirklich %s?", pipeline.ID, pipeline.Ref, label), func() {
+		go func() {
+			updated, err := run(projectID, pipeline.ID)
+
+			a.app.QueueUpdateDraw(func() {
+				if err != nil {
+					a.showNotification("❌ "+err.Error(), ColorDanger)
+					return
+				}
+				a.updatePipelineRow(list, *updated)
+				a.showNotification(fmt.Sprintf("Pipeline #%d: %s", updated.ID, updated.Status), ColorSuccess)
+			})
+		}()
+	})
+}
+
+func (a *App) updatePipelineRow(list *pipelineList, pipeline gitlab.Pipeline) {
+	for i, p := range list.pipelines {
+		if p.ID != pipeline.ID {
+			continue
+		}
+		list.pipelines[i] = pipeline
+		list.table.SetCell(i+1, 0, a.createPipelineCell(list.client, fmt.Sprint(list.proj.ID), pipeline))
+		if gitlab.IsActiveStatus(pipeline.Status) {
+			list.active = true
+		}
+		return
+	}
+}
+
+// handleJobAction retries, cancels or plays the selected job after asking
+// for confirmation and updates its row with the result.
+func (a *App) handleJobAction(list *jobList, action string) {
+	proj := list.proj
+	job, ok := list.selectedJob()
+	if !ok {
+		a.showNotification("Kein Job ausgewählt", ColorWarning)
+		return
+	}
+	if job.Bridge {
+		a.showNotification("Trigger-Jobs können hier nicht gesteuert werden – Enter öffnet die Downstream-Pipeline", ColorWarning)
+		return
+	}
+	client, _ := a.clientFor(proj)
+	projectID := fmt.Sprint(proj.ID)
+
+	var (
+		label string
+		run   func(projectID string, jobID int) (*gitlab.Job, error)
+	)
+	switch action {
+	case "retry":
+		if gitlab.IsActiveStatus(job.Status) || job.Status == "manual" {
+			a.showNotification("Job kann nicht neu gestartet werden", ColorWarning)
+			return
+		}
+		label, run = "neu starten", client.RetryJob
+	case "cancel":
+		if !gitlab.IsActiveStatus(job.Status) {
+			a.showNotification("Job läuft nicht – Abbrechen nicht möglich", ColorWarning)
+			return
+		}
+		label, run = "abbrechen", client.CancelJob
+	case "play":
+		if job.Status != "manual" {
+			a.showNotification("Nur manuelle Jobs (✋) können gestartet werden", ColorWarning)
+			return
+		}
+		label, run = "starten", client.PlayJob
+	default:
+		return
+	}
+
+	a.confirm(fmt.Sprintf("Job '%s' (#%d) wirklich %s?", job.Name, job.ID, label), func() {
+		go func() {
+			updated, err := run(projectID, job.ID)
+
+			a.app.QueueUpdateDraw(func() {
+				if err != nil {
+					a.showNotification("❌ "+err.Error(), ColorDanger)
+					return
+				}
+				// A retried job gets a new ID, so the old one is replaced.
+				// The jobs may have been refreshed meanwhile.
+				for i, j := range list.jobs {
+					if j.ID == job.ID {
+						updated.SchedulingType, updated.Needs = j.SchedulingType, j.Needs
+						jobs := slices.Clone(list.jobs)
+						jobs[i] = *updated
+						a.renderJobs(list, jobs, updated.ID)
+						break
+					}
+				}
+				a.showNotification(fmt.Sprintf("Job '%s': %s", updated.Name, updated.Status), ColorSuccess)
+			})
+		}()
+	})
+}
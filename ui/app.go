@@ -3,7 +3,12 @@
 package ui
 
 import (
+	"errors"
+	"strings"
+	"time"
+
 	"github.com/Youdontknowme720/Cimonv2/config"
+	"github.com/Youdontknowme720/Cimonv2/gitlab"
 	"github.com/rivo/tview"
 )
 
@@ -11,20 +16,64 @@ const (
 	PageHome     = "home"
 	PageSettings = "settings"
 	PagePipeline = "pipelines"
+	PageJob      = "JobPage"
+	PageJobLog   = "jobLog"
 	PageAddProj  = "addProject"
 	PageAddToken = "addToken"
+	PageEditProj = "editProject"
+	PageImport   = "importGroup"
+	PageConfirm  = "confirm"
+	PageTrigger  = "triggerPipeline"
+
+	PageNotification = "notification"
 )
 
 type App struct {
-	app            *tview.Application
-	pages          *tview.Pages
-	gitlabProjects []config.GitLabProject
-	token          string
+	app             *tview.Application
+	pages           *tview.Pages
+	gitlabProjects  []config.GitLabProject
+	gitlabInstances []config.GitLabInstance
+	clients         map[string]*gitlab.Client
+
+	refreshInterval       time.Duration
+	activeRefreshInterval time.Duration
+	pollers               map[string]chan struct{}
+	commitMessages        map[string]string
+	// pipelineFilters remembers the pipeline filter per project.
+	pipelineFilters map[string]gitlab.PipelineFilter
+	// jobTrail holds the parent pipelines of the shown downstream pipeline.
+	jobTrail []jobCrumb
+	// dashboard caches the latest pipeline per project for the home screen.
+	dashboard map[string]projectStatus
+
+	// startupErr and startupNotices are shown once the home screen is
+	// set up.
+	startupErr     error
+	startupNotices []string
+	// configStamp identifies the config file version that was loaded last.
+	configStamp fileStamp
 }
 
 func NewApp() *App {
-	token, projects := config.GetProjectData()
-	app := &App{tview.NewApplication(), tview.NewPages(), projects, token}
+	stamp, _ := statConfig()
+	cfg, err := config.ReadConfig()
+	app := &App{
+		app:            tview.NewApplication(),
+		pages:          tview.NewPages(),
+		gitlabProjects: cfg.Projects,
+		pollers:        make(map[string]chan struct{}),
+		commitMessages: make(map[string]string),
+		dashboard:      make(map[string]projectStatus),
+		configStamp:    stamp,
+
+		pipelineFilters: make(map[string]gitlab.PipelineFilter),
+	}
+	// tview does not own the terminal yet, so token commands may prompt.
+	clients, tokenErr := instanceClients(cfg.Instances)
+	app.gitlabInstances, app.clients = cfg.Instances, clients
+	app.startupErr = errors.Join(err, tokenErr)
+	app.startupNotices = cfg.Notices
+	app.refreshInterval, app.activeRefreshInterval = cfg.PollIntervals()
 	return app
 }
 
@@ -36,4 +85,35 @@ func (a *App) Setup() {
 	home := a.createHomeScreen(a.gitlabProjects)
 	a.pages.AddPage(PageHome, home, true, true)
 	a.app.SetRoot(a.pages, true)
+	notice := strings.Join(a.startupNotices, "\n")
+	if a.startupErr != nil {
+		a.showNotification(strings.TrimSpace(notice+"\n❌ "+a.startupErr.Error()), ColorDanger)
+	} else if notice != "" {
+		a.showNotification(notice, ColorSuccess)
+	}
+	a.openCurrentRepository()
+	a.watchConfig()
+}
+
+// instanceClients builds one API client per instance. Instances whose token
+// cannot be resolved still get a client without token; the errors are
+// returned. A token_command may take a while or prompt on the terminal, so
+// this must not run on the UI goroutine.
+func instanceClients(instances []config.GitLabInstance) (map[string]*gitlab.Client, error) {
+	var errs []error
+	clients := make(map[string]*gitlab.Client, len(instances))
+	for _, inst := range instances {
+		token, err := inst.ResolveToken()
+		if err != nil {
+			errs = append(errs, err)
+		}
+		clients[inst.Name] = gitlab.NewClient(inst.URL, token)
+	}
+	return clients, errors.Join(errs...)
+}
+
+// clientFor returns the API client of the instance the project belongs to.
+func (a *App) clientFor(proj config.GitLabProject) (*gitlab.Client, bool) {
+	client, ok := a.clients[proj.Instance]
+	return client, ok
 }
@@ -0,0 +1,202 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Youdontknowme720/Cimonv2/config"
+	"github.com/Youdontknowme720/Cimonv2/gitlab"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// dashboardWorkers limits how many projects are queried at the same time.
+const dashboardWorkers = 8
+
+// projectStatus is the latest pipeline of a project shown on the home
+// screen. A nil pipeline without error means the project has none yet.
+type projectStatus struct {
+	pipeline *gitlab.Pipeline
+	err      error
+}
+
+func projectKey(p config.GitLabProject) string {
+	return fmt.Sprintf("%s/%d", p.Instance, p.ID)
+}
+
+// refreshDashboard fetches the latest pipeline of every project
+// concurrently and updates the project rows of the home table.
+func (a *App) refreshDashboard(table *tview.Table, done func(active bool)) {
+	projects := a.gitlabProjects
+	clients := a.clients
+
+	go func() {
+		statuses := make([]projectStatus, len(projects))
+		sem := make(chan struct{}, dashboardWorkers)
+		var wg sync.WaitGroup
+		for i, proj := range projects {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+				statuses[i] = fetchProjectStatus(clients[proj.Instance], proj)
+			}()
+		}
+		wg.Wait()
+
+		a.app.QueueUpdateDraw(func() {
+			active := false
+			for i, proj := range projects {
+				a.dashboard[projectKey(proj)] = statuses[i]
+				if p := statuses[i].pipeline; p != nil && gitlab.IsActiveStatus(p.Status) {
+					active = true
+				}
+			}
+			a.setProjectRows(table, projects)
+			done(active)
+		})
+	}()
+}
+
+func fetchProjectStatus(client *gitlab.Client, proj config.GitLabProject) projectStatus {
+	if client == nil {
+		return projectStatus{err: fmt.Errorf("instance %q is not configured", proj.Instance)}
+	}
+	projectID := fmt.Sprint(proj.ID)
+	pipelines, err := client.GetAllPipelines(projectID, 1, gitlab.PipelineFilter{})
+	if err != nil || len(pipelines) == 0 {
+		return projectStatus{err: err}
+	}
+	// The list does not include timings, so fetch the pipeline itself.
+	pipeline, err := client.GetPipeline(projectID, pipelines[0].ID)
+	if err != nil {
+		return projectStatus{pipeline: &pipelines[0]}
+	}
+	return projectStatus{pipeline: pipeline}
+}
+
+// setProjectRows renders the projects grouped by instance below the
+// settings buttons, with failed projects first in each group. The selected
+// project stays selected.
+func (a *App) setProjectRows(table *tview.Table, projects []config.GitLabProject) {
+	const firstRow = 5
+
+	selectedKey := ""
+	row, _ := table.GetSelection()
+	if proj, ok := table.GetCell(row, 0).GetReference().(config.GitLabProject); ok {
+		selectedKey = projectKey(proj)
+	}
+	for r := table.GetRowCount() - 1; r >= firstRow; r-- {
+		table.RemoveRow(r)
+	}
+
+	row = firstRow
+	for _, group := range a.groupProjectsByInstance(projects) {
+		groupCell := tview.NewTableCell(fmt.Sprintf("🌐 %s", group.title)).
+			SetAlign(tview.AlignLeft).
+			SetSelectable(false).
+			SetTextColor(ColorAccent).
+			SetAttributes(tcell.AttrBold)
+		table.SetCell(row, 0, groupCell)
+		for col, title := range []string{"Ref", "Alter", "Dauer"} {
+			table.SetCell(row, col+1, tview.NewTableCell(title).
+				SetSelectable(false).
+				SetTextColor(tcell.ColorGray))
+		}
+		row++
+
+		sorted := slices.Clone(group.projects)
+		slices.SortStableFunc(sorted, func(x, y config.GitLabProject) int {
+			return a.statusRank(x) - a.statusRank(y)
+		})
+
+		for _, project := range sorted {
+			for col, text := range a.projectColumns(project) {
+				cell := tview.NewTableCell(text).
+					SetAlign(tview.AlignLeft).
+					SetSelectable(true).
+					SetReference(project).
+					SetTextColor(ColorText)
+				if col > 0 {
+					cell.SetTextColor(tcell.ColorLightGray)
+				}
+				table.SetCell(row, col, cell)
+			}
+			if projectKey(project) == selectedKey {
+				table.Select(row, 0)
+			}
+			row++
+		}
+	}
+}
+
+// statusRank sorts failed projects before all others.
+func (a *App) statusRank(p config.GitLabProject) int {
+	if s := a.dashboard[projectKey(p)]; s.pipeline != nil && s.pipeline.Status == "failed" {
+		return 0
+	}
+	return 1
+}
+
+// projectColumns returns name, ref, age and duration of the latest
+// pipeline of a project.
+func (a *App) projectColumns(p config.GitLabProject) []string {
+	status, ok := a.dashboard[projectKey(p)]
+	switch {
+	case !ok:
+		return []string{fmt.Sprintf("  ⏳ %s ", p.Name), "", "", ""}
+	case status.err != nil:
+		return []string{fmt.Sprintf("  ❗ %s ", p.Name), "Fehler", "", ""}
+	case status.pipeline == nil:
+		return []string{fmt.Sprintf("  ➖ %s ", p.Name), "keine Pipelines", "", ""}
+	}
+	pipeline := status.pipeline
+	return []string{
+		fmt.Sprintf("  %s %s ", gitlab.StatusEmoji(pipeline.Status), p.Name),
+		pipeline.Ref + " ",
+		formatAge(pipeline.CreatedAt) + " ",
+		formatDuration(pipelineDuration(pipeline)),
+	}
+}
+
+// pipelineDuration returns how long the pipeline ran, or has been running
+// so far.
+func pipelineDuration(p *gitlab.Pipeline) time.Duration {
+	if p.Duration > 0 {
+		return time.Duration(p.Duration) * time.Second
+	}
+	started, err := time.Parse(time.RFC3339, p.StartedAt)
+	if err != nil || !gitlab.IsActiveStatus(p.Status) {
+		return 0
+	}
+	return time.Since(started)
+}
+
+func formatDuration(d time.Duration) string {
+	if d <= 0 {
+		return "-"
+	}
+	return d.Round(time.Second).String()
+}
+
+// formatAge turns an API timestamp into a short relative time like "5m".
+func formatAge(timestamp string) string {
+	t, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		return "-"
+	}
+	age := time.Since(t)
+	switch {
+	case age < time.Minute:
+		return "gerade eben"
+	case age < time.Hour:
+		return fmt.Sprintf("vor %dm", int(age.Minutes()))
+	case age < 24*time.Hour:
+		return fmt.Sprintf("vor %dh", int(age.Hours()))
+	default:
+		return fmt.Sprintf("vor %dd", int(age.Hours()/24))
+	}
+}
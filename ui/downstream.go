@@ -0,0 +1,102 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Youdontknowme720/Cimonv2/config"
+	"github.com/Youdontknowme720/Cimonv2/gitlab"
+)
+
+// jobCrumb is a parent pipeline on the way to the shown downstream
+// pipeline, with the trigger job that was followed.
+type jobCrumb struct {
+	proj       config.GitLabProject
+	pipelineID int
+	bridge     gitlab.Job
+}
+
+// showDownstreamPipeline opens the job page of the pipeline started by the
+// trigger job bridge. The downstream pipeline may belong to another
+// project of the same instance.
+func (a *App) showDownstreamPipeline(list *jobList, bridge gitlab.Job) {
+	downstream := bridge.DownstreamPipeline
+	if downstream == nil {
+		a.showNotification(fmt.Sprintf("'%s' hat noch keine Downstream-Pipeline gestartet", bridge.Name), ColorWarning)
+		return
+	}
+
+	open := func(proj config.GitLabProject) {
+		a.jobTrail = append(a.jobTrail, jobCrumb{proj: list.proj, pipelineID: list.pipelineID, bridge: bridge})
+		a.pages.AddPage(PageJob, a.newJobPage(proj, downstream.ID, 0), true, true)
+		a.pages.SwitchToPage(PageJob)
+	}
+
+	if downstream.ProjectID == 0 || downstream.ProjectID == list.proj.ID {
+		open(list.proj)
+		return
+	}
+	if proj, ok := a.findProject(list.proj.Instance, downstream.ProjectID); ok {
+		open(proj)
+		return
+	}
+
+	a.showNotification(fmt.Sprintf("Lade Downstream-Pipeline #%d...", downstream.ID), ColorSuccess)
+	client, _ := a.clientFor(list.proj)
+	go func() {
+		project, err := client.GetProject(fmt.Sprint(downstream.ProjectID))
+
+		a.app.QueueUpdateDraw(func() {
+			if err != nil {
+				a.showNotification("❌ "+err.Error(), ColorDanger)
+				return
+			}
+			open(config.GitLabProject{
+				ID:       project.ID,
+				Name:     project.Name,
+				Path:     project.PathWithNamespace,
+				Instance: list.proj.Instance,
+			})
+		})
+	}()
+}
+
+// jobPageBack returns to the parent pipeline of a downstream pipeline, or
+// to the pipeline page.
+func (a *App) jobPageBack() {
+	if len(a.jobTrail) == 0 {
+		a.showNotification("Zurück zur Pipeline-Ansicht...", ColorSuccess)
+		a.pages.SwitchToPage(PagePipeline)
+		return
+	}
+
+	parent := a.jobTrail[len(a.jobTrail)-1]
+	a.jobTrail = a.jobTrail[:len(a.jobTrail)-1]
+	a.pages.AddPage(PageJob, a.newJobPage(parent.proj, parent.pipelineID, parent.bridge.ID), true, true)
+	a.pages.SwitchToPage(PageJob)
+}
+
+// jobTrailText returns the breadcrumb from the outermost pipeline to the
+// pipeline of list, or "" if it is not a downstream pipeline.
+func (a *App) jobTrailText(proj config.GitLabProject, pipelineID int) string {
+	if len(a.jobTrail) == 0 {
+		return ""
+	}
+	var parts []string
+	for _, crumb := range a.jobTrail {
+		parts = append(parts, fmt.Sprintf("%s #%d", crumb.proj.Name, crumb.pipelineID), "↪ "+crumb.bridge.Name)
+	}
+	parts = append(parts, fmt.Sprintf("%s #%d", proj.Name, pipelineID))
+	return strings.Join(parts, " › ")
+}
+
+// downstreamLabel describes the downstream pipeline of a trigger job.
+func downstreamLabel(job gitlab.Job) string {
+	if !job.Bridge {
+		return ""
+	}
+	if job.DownstreamPipeline == nil {
+		return "↪ noch nicht gestartet"
+	}
+	return fmt.Sprintf("↪ %s Pipeline #%d", gitlab.StatusEmoji(job.DownstreamPipeline.Status), job.DownstreamPipeline.ID)
+}
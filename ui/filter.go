@@ -0,0 +1,193 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Youdontknowme720/Cimonv2/gitlab"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+const (
+	filterBarHeight = 5
+	filterAll       = "alle"
+)
+
+// createFilterBar returns the form to filter the pipelines of list. It is
+// hidden until toggleFilterBar shows it.
+func (a *App) createFilterBar(list *pipelineList) *tview.Form {
+	form := tview.NewForm().SetHorizontal(true)
+	form.SetBorderPadding(0, 0, 1, 1)
+	form.SetBorder(true).
+		SetTitle(" 🔍 Filter (Ctrl+S: anwenden, Esc: schließen) ").
+		SetTitleAlign(tview.AlignLeft)
+	form.SetFieldBackgroundColor(ColorOrange)
+	form.SetFieldTextColor(tcell.ColorBlack)
+	form.SetLabelColor(tcell.ColorWhite)
+	form.SetTitleColor(ColorPink)
+	form.SetBorderColor(ColorOrange)
+	form.SetBackgroundColor(ColorBlue)
+	form.SetButtonBackgroundColor(ColorOrange)
+	form.SetButtonTextColor(tcell.ColorWhite)
+
+	statuses := append([]string{filterAll}, gitlab.PipelineStatuses...)
+	sources := append([]string{filterAll}, gitlab.PipelineSources...)
+
+	form.AddInputField("Ref", "", 16, nil, nil).
+		AddDropDown("Status", statuses, 0, nil).
+		AddDropDown("Source", sources, 0, nil).
+		AddInputField("User", "", 12, nil, nil).
+		AddInputField("Seit", "", 16, nil, nil)
+	// The option lists show the full names.
+	form.GetFormItemByLabel("Status").(*tview.DropDown).SetFieldWidth(10)
+	form.GetFormItemByLabel("Source").(*tview.DropDown).SetFieldWidth(12)
+	form.GetFormItemByLabel("Seit").(*tview.InputField).SetPlaceholder("24h, 7d, 2024-01-31")
+
+	apply := func() {
+		filter, err := readFilterBar(form)
+		if err != nil {
+			a.showNotification(err.Error(), ColorWarning)
+			return
+		}
+		a.setPipelineFilter(list, filter)
+		a.toggleFilterBar(list)
+	}
+	reset := func() {
+		a.setPipelineFilter(list, gitlab.PipelineFilter{})
+		a.toggleFilterBar(list)
+	}
+
+	form.AddButton("Anwenden", apply).
+		AddButton("Zurücksetzen", reset)
+
+	form.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyCtrlS:
+			apply()
+			return nil
+		case tcell.KeyEsc:
+			a.toggleFilterBar(list)
+			return nil
+		}
+		return event
+	})
+
+	return form
+}
+
+// toggleFilterBar shows the filter bar filled with the active filter, or
+// hides it again.
+func (a *App) toggleFilterBar(list *pipelineList) {
+	if list.filterVisible {
+		list.filterVisible = false
+		list.container.ResizeItem(list.filterBar, 0, 0)
+		a.app.SetFocus(list.table)
+		return
+	}
+
+	setFilterBar(list.filterBar, list.filter)
+	list.filterVisible = true
+	list.container.ResizeItem(list.filterBar, filterBarHeight, 0)
+	list.filterBar.SetFocus(0)
+	a.app.SetFocus(list.filterBar)
+}
+
+// setPipelineFilter reloads the pipelines of list with filter and
+// remembers it for the project.
+func (a *App) setPipelineFilter(list *pipelineList, filter gitlab.PipelineFilter) {
+	list.filter = filter
+	a.pipelineFilters[projectKey(list.proj)] = filter
+	list.header.SetText(pipelineHeaderText(list.proj, filter))
+	list.container.ResizeItem(list.header, pipelineHeaderHeight(filter), 0)
+	a.refreshPipelines(list)
+}
+
+func setFilterBar(form *tview.Form, filter gitlab.PipelineFilter) {
+	selectOption := func(label, value string) {
+		dropDown := form.GetFormItemByLabel(label).(*tview.DropDown)
+		options := gitlab.PipelineStatuses
+		if label == "Source" {
+			options = gitlab.PipelineSources
+		}
+		dropDown.SetCurrentOption(slices.Index(options, value) + 1)
+	}
+
+	form.GetFormItemByLabel("Ref").(*tview.InputField).SetText(filter.Ref)
+	selectOption("Status", filter.Status)
+	selectOption("Source", filter.Source)
+	form.GetFormItemByLabel("User").(*tview.InputField).SetText(filter.Username)
+	since := ""
+	if !filter.UpdatedAfter.IsZero() {
+		since = filter.UpdatedAfter.Format("2006-01-02 15:04")
+	}
+	form.GetFormItemByLabel("Seit").(*tview.InputField).SetText(since)
+}
+
+func readFilterBar(form *tview.Form) (gitlab.PipelineFilter, error) {
+	option := func(label string) string {
+		_, value := form.GetFormItemByLabel(label).(*tview.DropDown).GetCurrentOption()
+		if value == filterAll {
+			return ""
+		}
+		return value
+	}
+	text := func(label string) string {
+		return strings.TrimSpace(form.GetFormItemByLabel(label).(*tview.InputField).GetText())
+	}
+
+	updatedAfter, err := parseSince(text("Seit"), time.Now())
+	if err != nil {
+		return gitlab.PipelineFilter{}, err
+	}
+	return gitlab.PipelineFilter{
+		Ref:          text("Ref"),
+		Status:       option("Status"),
+		Source:       option("Source"),
+		Username:     strings.TrimPrefix(text("User"), "@"),
+		UpdatedAfter: updatedAfter,
+	}, nil
+}
+
+// parseSince parses a duration before now like "90m", "24h" or "7d", or a
+// date with optional time. An empty text means no limit.
+func parseSince(text string, now time.Time) (time.Time, error) {
+	if text == "" {
+		return time.Time{}, nil
+	}
+	if days, ok := strings.CutSuffix(text, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(text); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, text, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("Ungültige Zeitangabe %q, erwartet z.B. 24h, 7d oder 2024-01-31", text)
+}
+
+// describeFilter returns the active filter for the pipeline header.
+func describeFilter(filter gitlab.PipelineFilter) string {
+	var parts []string
+	add := func(label, value string) {
+		if value != "" {
+			parts = append(parts, label+": "+value)
+		}
+	}
+	add("Ref", filter.Ref)
+	add("Status", filter.Status)
+	add("Source", filter.Source)
+	add("User", filter.Username)
+	if !filter.UpdatedAfter.IsZero() {
+		add("Seit", filter.UpdatedAfter.Format("02.01.2006 15:04"))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -15,3 +15,20 @@ func newSelectableTable() *tview.Table {
 	table.SetBorder(true)
 	return table
 }
+
+// confirm asks the user to confirm an action in a modal dialog and runs
+// onConfirm if they agree.
+func (a *App) confirm(message string, onConfirm func()) {
+	modal := tview.NewModal().
+		SetText(message).
+		AddButtons([]string{"Ja", "Abbrechen"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			a.pages.RemovePage(PageConfirm)
+			if buttonIndex == 0 {
+				onConfirm()
+			}
+		})
+	modal.SetBorderColor(ColorOrange)
+
+	a.pages.AddPage(PageConfirm, modal, false, true)
+}
@@ -32,6 +32,11 @@ func (a *App) createHomeScreen(projects []config.GitLabProject) *tview.Flex {
 
 	table := a.createStyledProjectTable(projects)
 
+	a.refreshDashboard(table, func(bool) {})
+	a.poll(PageHome, func(done func(active bool)) {
+		a.refreshDashboard(table, done)
+	})
+
 	mainContainer.
 		AddItem(header, 3, 0, false).
 		AddItem(table, 0, 2, true)
@@ -76,19 +81,9 @@ func (a *App) createStyledProjectTable(projects []config.GitLabProject) *tview.T
 		table.SetCell(pos[0], pos[1], cell)
 	}
 
-	table.SetCell(2, 0, tview.NewTableCell("").SetSelectable(false))
+	table.SetCell(4, 0, tview.NewTableCell("").SetSelectable(false))
 
-	for i, project := range projects {
-		cellText := fmt.Sprintf("- %s ", project.Name)
-
-		cell := tview.NewTableCell(cellText).
-			SetAlign(tview.AlignLeft).
-			SetSelectable(true).
-			SetReference(project).
-			SetTextColor(ColorText)
-
-		table.SetCell(3+i, 0, cell)
-	}
+	a.setProjectRows(table, projects)
 
 	table.SetSelectedFunc(func(row, column int) {
 		a.handleHomeSelected(row, column, table)
@@ -106,6 +101,41 @@ func (a *App) createStyledProjectTable(projects []config.GitLabProject) *tview.T
 	return table
 }
 
+type projectGroup struct {
+	title    string
+	projects []config.GitLabProject
+}
+
+// groupProjectsByInstance groups projects in the order the instances are
+// configured. Projects referencing an unknown instance are listed last.
+func (a *App) groupProjectsByInstance(projects []config.GitLabProject) []projectGroup {
+	var groups []projectGroup
+	known := make(map[string]bool, len(a.gitlabInstances))
+
+	for _, inst := range a.gitlabInstances {
+		known[inst.Name] = true
+		group := projectGroup{title: fmt.Sprintf("%s (%s)", inst.Name, inst.URL)}
+		for _, project := range projects {
+			if project.Instance == inst.Name {
+				group.projects = append(group.projects, project)
+			}
+		}
+		groups = append(groups, group)
+	}
+
+	unknown := projectGroup{title: "Unbekannte Instanz"}
+	for _, project := range projects {
+		if !known[project.Instance] {
+			unknown.projects = append(unknown.projects, project)
+		}
+	}
+	if len(unknown.projects) > 0 {
+		groups = append(groups, unknown)
+	}
+
+	return groups
+}
+
 func (a *App) createSettingsButtons() map[[2]int]*tview.TableCell {
 	buttons := make(map[[2]int]*tview.TableCell)
 
@@ -123,8 +153,24 @@ func (a *App) createSettingsButtons() map[[2]int]*tview.TableCell {
 		SetTextColor(tcell.ColorWhite).
 		SetBackgroundColor(ColorBlue)
 
+	importGroupCell := tview.NewTableCell("+ Import Group").
+		SetAlign(tview.AlignLeft).
+		SetSelectable(true).
+		SetReference("ImportGroup").
+		SetTextColor(tcell.ColorWhite).
+		SetBackgroundColor(ColorBlue)
+
+	manageProjectsCell := tview.NewTableCell("⚙ Manage Projects").
+		SetAlign(tview.AlignLeft).
+		SetSelectable(true).
+		SetReference("ManageProj").
+		SetTextColor(tcell.ColorWhite).
+		SetBackgroundColor(ColorBlue)
+
 	buttons[[2]int{0, 0}] = addProjectCell
-	buttons[[2]int{1, 0}] = addTokenCell
+	buttons[[2]int{1, 0}] = importGroupCell
+	buttons[[2]int{2, 0}] = addTokenCell
+	buttons[[2]int{3, 0}] = manageProjectsCell
 
 	return buttons
 }
@@ -144,8 +190,12 @@ func (a *App) handleHomeSelected(row, column int, table *tview.Table) {
 
 	switch v := ref.(type) {
 	case config.GitLabProject:
+		if _, ok := a.clientFor(v); !ok {
+			a.showNotification(fmt.Sprintf("Instanz '%s' ist nicht konfiguriert", v.Instance), ColorDanger)
+			return
+		}
 		a.showNotification(fmt.Sprintf("Lade Pipeline für %s...", v.Name), ColorSuccess)
-		page := a.createPipelinePage(v)
+		page := a.createPipelinePage(v, a.pipelineFilters[projectKey(v)])
 		a.pages.AddPage(PagePipeline, page, true, true)
 		a.pages.SwitchToPage(PagePipeline)
 
@@ -155,6 +205,10 @@ func (a *App) handleHomeSelected(row, column int, table *tview.Table) {
 			a.handleAddingProject()
 		case "AddToken":
 			a.handleAddingToken()
+		case "ImportGroup":
+			a.handleImportGroup()
+		case "ManageProj":
+			a.showProjectSettings()
 		default:
 			a.showNotification("Unbekannter Button – Auswahl ignoriert", ColorDanger)
 		}
@@ -169,15 +223,17 @@ func (a *App) showNotification(message string, color tcell.Color) {
 		SetText(message).
 		SetTextColor(color).
 		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
-			a.pages.HidePage("notification")
+			a.pages.HidePage(PageNotification)
 		})
 
-	a.pages.AddPage("notification", modal, false, true)
+	a.pages.AddPage(PageNotification, modal, false, true)
 
+	// Longer messages like config errors stay a bit longer.
+	duration := min(2*time.Second+time.Duration(len(message))*30*time.Millisecond, 10*time.Second)
 	go func() {
-		time.Sleep(2 * time.Second)
+		time.Sleep(duration)
 		a.app.QueueUpdateDraw(func() {
-			a.pages.HidePage("notification")
+			a.pages.HidePage(PageNotification)
 		})
 	}()
 }
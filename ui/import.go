@@ -0,0 +1,269 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Youdontknowme720/Cimonv2/config"
+	"github.com/Youdontknowme720/Cimonv2/gitlab"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// importEntry is one project of the group in the import checklist.
+type importEntry struct {
+	project    gitlab.Project
+	configured bool
+	checked    bool
+}
+
+// handleImportGroup shows a form to load all projects of a group including
+// its subgroups and add the ticked ones to the config in one save.
+func (a *App) handleImportGroup() {
+	if len(a.gitlabInstances) == 0 {
+		a.showNotification("Bitte zuerst eine GitLab-Instanz mit Token anlegen", ColorWarning)
+		return
+	}
+
+	instanceNames := make([]string, len(a.gitlabInstances))
+	for i, inst := range a.gitlabInstances {
+		instanceNames[i] = inst.Name
+	}
+
+	form := tview.NewForm().
+		AddDropDown("Instance", instanceNames, 0, nil).
+		AddInputField("Group", "", 50, nil, nil)
+
+	form.SetBorder(true).
+		SetTitle(" 📦 Gruppe importieren ").
+		SetTitleAlign(tview.AlignCenter)
+	form.SetFieldBackgroundColor(ColorOrange)
+	form.SetFieldTextColor(tcell.ColorBlack)
+	form.SetLabelColor(tcell.ColorWhite)
+	form.SetTitleColor(ColorPink)
+	form.SetBorderColor(ColorOrange)
+	form.SetBackgroundColor(ColorBlue)
+	form.SetButtonBackgroundColor(ColorOrange)
+	form.SetButtonTextColor(tcell.ColorWhite)
+
+	instanceDropDown := form.GetFormItemByLabel("Instance").(*tview.DropDown)
+	groupField := form.GetFormItemByLabel("Group").(*tview.InputField)
+	groupField.SetPlaceholder("group oder group/subgroup")
+
+	table := newSelectableTable()
+	table.SetBorderColor(ColorOrange).
+		SetTitle(" Projekte – Space auswählen | a alle | Ctrl+S importieren ").
+		SetTitleAlign(tview.AlignLeft).
+		SetTitleColor(ColorPink).
+		SetBackgroundColor(ColorBlue)
+	table.SetSelectedStyle(tcell.StyleDefault.
+		Background(ColorBlue).
+		Foreground(ColorPink).
+		Bold(true))
+
+	var (
+		entries  []*importEntry
+		instance string
+		loading  bool
+	)
+
+	loadFunc := func() {
+		group := strings.Trim(strings.TrimSpace(groupField.GetText()), "/")
+		if group == "" {
+			a.showNotification("Bitte einen Gruppenpfad angeben", ColorWarning)
+			return
+		}
+		if loading {
+			return
+		}
+		_, name := instanceDropDown.GetCurrentOption()
+		client := a.clients[name]
+
+		loading = true
+		entries = nil
+		table.Clear()
+		table.SetCell(0, 0, tview.NewTableCell(fmt.Sprintf("⏳ Lade Projekte von %s...", group)).
+			SetTextColor(ColorText).
+			SetSelectable(false))
+
+		go func() {
+			projects, err := client.GetGroupProjects(group)
+
+			a.app.QueueUpdateDraw(func() {
+				loading = false
+				table.Clear()
+				if err != nil {
+					msg := "❌ " + err.Error()
+					if gitlab.IsNotFound(err) {
+						msg = fmt.Sprintf("❌ Gruppe %q auf %s nicht gefunden oder kein Zugriff mit diesem Token", group, name)
+					}
+					table.SetCell(0, 0, tview.NewTableCell(msg).
+						SetTextColor(ColorDanger).
+						SetSelectable(false))
+					return
+				}
+
+				instance = name
+				for _, p := range projects {
+					configured := a.isProjectConfigured(name, p.ID)
+					entries = append(entries, &importEntry{project: p, configured: configured, checked: !configured})
+				}
+				setImportRows(table, entries)
+				if len(entries) > 0 {
+					a.app.SetFocus(table)
+				}
+			})
+		}()
+	}
+
+	toggle := func(all bool) {
+		row, _ := table.GetSelection()
+		switch {
+		case all:
+			check := false
+			for _, e := range entries {
+				if !e.configured && !e.checked {
+					check = true
+				}
+			}
+			for _, e := range entries {
+				e.checked = check && !e.configured
+			}
+		case row > 0 && row <= len(entries) && !entries[row-1].configured:
+			entries[row-1].checked = !entries[row-1].checked
+		}
+		setImportRows(table, entries)
+	}
+
+	abortFunc := func() {
+		a.pages.RemovePage(PageImport)
+		a.pages.SwitchToPage(PageHome)
+	}
+
+	saveFunc := func() {
+		var selected []config.GitLabProject
+		for _, e := range entries {
+			if e.checked && !e.configured {
+				selected = append(selected, config.GitLabProject{
+					ID:       e.project.ID,
+					Name:     e.project.PathWithNamespace,
+					Path:     e.project.PathWithNamespace,
+					Instance: instance,
+				})
+			}
+		}
+		if len(selected) == 0 {
+			a.showNotification("Keine Projekte ausgewählt", ColorWarning)
+			return
+		}
+		added, err := config.AddNewProjects(selected)
+		if err != nil {
+			a.showNotification("❌ "+err.Error(), ColorDanger)
+			return
+		}
+		a.pages.RemovePage(PageImport)
+		a.reloadHome()
+		a.showNotification(fmt.Sprintf("%d Projekte importiert", added), ColorSuccess)
+	}
+
+	groupField.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEnter {
+			loadFunc()
+		}
+	})
+	form.AddButton("Load", loadFunc)
+	form.AddButton("Import", saveFunc)
+	form.AddButton("Abort", abortFunc)
+
+	table.SetSelectedFunc(func(row, column int) { toggle(false) })
+	table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyRune:
+			switch event.Rune() {
+			case ' ':
+				toggle(false)
+				return nil
+			case 'a':
+				toggle(true)
+				return nil
+			}
+		case tcell.KeyTab, tcell.KeyBacktab:
+			a.app.SetFocus(form)
+			return nil
+		}
+		return event
+	})
+
+	flex := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(form, 9, 0, true).
+		AddItem(table, 0, 1, false)
+
+	flex.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyCtrlS:
+			saveFunc()
+			return nil
+		case event.Key() == tcell.KeyCtrlB || event.Key() == tcell.KeyEsc:
+			abortFunc()
+			return nil
+		}
+		return event
+	})
+
+	a.pages.AddPage(PageImport, flex, true, true)
+	a.pages.SwitchToPage(PageImport)
+}
+
+// setImportRows renders the checklist. Row i+1 belongs to entries[i].
+func setImportRows(table *tview.Table, entries []*importEntry) {
+	row, _ := table.GetSelection()
+	table.Clear()
+
+	selected := 0
+	for _, e := range entries {
+		if e.checked {
+			selected++
+		}
+	}
+	table.SetCell(0, 0, tview.NewTableCell(fmt.Sprintf("Projekte (%d, %d ausgewählt)", len(entries), selected)).
+		SetTextColor(ColorText).
+		SetSelectable(false).
+		SetAttributes(tcell.AttrBold))
+
+	if len(entries) == 0 {
+		table.SetCell(1, 0, tview.NewTableCell("Keine Projekte in dieser Gruppe").
+			SetTextColor(ColorText).
+			SetSelectable(false))
+		return
+	}
+
+	for i, e := range entries {
+		box, color := "[ ]", ColorText
+		switch {
+		case e.configured:
+			box, color = "[✓] bereits konfiguriert –", tcell.ColorGray
+		case e.checked:
+			box = "[x]"
+		}
+		// The boxes would be read as style tags.
+		table.SetCell(i+1, 0, tview.NewTableCell(tview.Escape(box+" "+e.project.PathWithNamespace)).
+			SetTextColor(color))
+	}
+	table.Select(min(max(row, 1), len(entries)), 0)
+}
+
+// isProjectConfigured reports whether the project is already in the config.
+func (a *App) isProjectConfigured(instance string, projectID int) bool {
+	_, ok := a.findProject(instance, projectID)
+	return ok
+}
+
+// findProject returns the configured project with projectID on instance.
+func (a *App) findProject(instance string, projectID int) (config.GitLabProject, bool) {
+	for _, p := range a.gitlabProjects {
+		if p.Instance == instance && p.ID == projectID {
+			return p, true
+		}
+	}
+	return config.GitLabProject{}, false
+}
@@ -0,0 +1,64 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Youdontknowme720/Cimonv2/gitlab"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// drawLines draws p on a simulated screen of width x height cells and
+// returns the text of each line without trailing spaces.
+func drawLines(t *testing.T, p tview.Primitive, width, height int) []string {
+	t.Helper()
+	screen := tcell.NewSimulationScreen("UTF-8")
+	if err := screen.Init(); err != nil {
+		t.Fatal(err)
+	}
+	defer screen.Fini()
+	screen.SetSize(width, height)
+
+	p.SetRect(0, 0, width, height)
+	p.Draw(screen)
+	screen.Show()
+
+	cells, w, h := screen.GetContents()
+	lines := make([]string, h)
+	for y := range h {
+		var line strings.Builder
+		for x := range w {
+			if runes := cells[y*w+x].Runes; len(runes) > 0 {
+				line.WriteString(string(runes))
+			} else {
+				line.WriteByte(' ')
+			}
+		}
+		lines[y] = strings.TrimRight(line.String(), " ")
+	}
+	return lines
+}
+
+func TestSetImportRows(t *testing.T) {
+	entries := []*importEntry{
+		{project: gitlab.Project{PathWithNamespace: "group/checked"}, checked: true},
+		{project: gitlab.Project{PathWithNamespace: "group/unchecked"}},
+		{project: gitlab.Project{PathWithNamespace: "group/[tag]"}, configured: true},
+	}
+	table := tview.NewTable().SetSelectable(true, false)
+	setImportRows(table, entries)
+
+	lines := drawLines(t, table, 60, 4)
+	want := []string{
+		"Projekte (3, 1 ausgewählt)",
+		"[x] group/checked",
+		"[ ] group/unchecked",
+		"[✓] bereits konfiguriert – group/[tag]",
+	}
+	for i, line := range want {
+		if lines[i] != line {
+			t.Errorf("line %d = %q, want %q", i, lines[i], line)
+		}
+	}
+}
@@ -4,73 +4,148 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/Youdontknowme720/Cimonv2/config"
 	"github.com/Youdontknowme720/Cimonv2/gitlab"
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 )
 
-func (a *App) createJobPage(projectID int, pipelineID int) tview.Primitive {
-	container := tview.NewFlex().SetDirection(tview.FlexRow)
+// jobView is the way the jobs of a pipeline are laid out.
+type jobView int
+
+const (
+	jobViewList jobView = iota
+	jobViewGraph
+	jobViewDAG
+)
 
-	header := a.createJobHeader(projectID, pipelineID)
+// jobList is the state behind the job table of a pipeline.
+type jobList struct {
+	table      *tview.Table
+	header     *tview.TextView
+	proj       config.GitLabProject
+	pipelineID int
+	jobs       gitlab.Jobs
+	view       jobView
+
+	// container and info show the dependencies of the selected job in
+	// the DAG view. needsErr is set if the needs could not be loaded.
+	container *tview.Flex
+	info      *tview.TextView
+	needsErr  error
+
+	// stages is the stage order of the pipeline, loaded once for the
+	// graph and DAG views.
+	stages []string
+
+	// trail is the breadcrumb of a downstream pipeline.
+	trail string
+}
 
-	table := a.handleJobClick(fmt.Sprint(projectID), pipelineID)
-	a.styleJobTable(table, pipelineID)
+// createJobPage creates the job page of a pipeline opened from the
+// pipeline page.
+func (a *App) createJobPage(proj config.GitLabProject, pipelineID int) tview.Primitive {
+	a.jobTrail = nil
+	return a.newJobPage(proj, pipelineID, 0)
+}
+
+// newJobPage creates the job page of a pipeline and selects the job with
+// selectedID once the jobs are loaded.
+func (a *App) newJobPage(proj config.GitLabProject, pipelineID, selectedID int) tview.Primitive {
+	container := tview.NewFlex().SetDirection(tview.FlexRow)
+
+	list := a.handleJobClick(proj, pipelineID, selectedID)
+	list.trail = a.jobTrailText(proj, pipelineID)
+	header := a.createJobHeader(list)
+	list.header = header
+	list.container = container
+	list.info = tview.NewTextView().SetDynamicColors(true)
+	list.info.SetBackgroundColor(ColorBlue)
+	list.info.SetBorder(true).
+		SetBorderColor(ColorOrange).
+		SetTitle(" 🔗 Abhängigkeiten ").
+		SetTitleAlign(tview.AlignLeft).
+		SetTitleColor(ColorPink)
+	table := list.table
+	a.styleJobTable(list)
 
 	table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
 		switch event.Key() {
 		case tcell.KeyRune:
 			switch event.Rune() {
 			case 'b', 'B':
-				a.showNotification("Zurück zur Pipeline-Ansicht...", ColorSuccess)
-				a.pages.SwitchToPage(PagePipeline)
+				a.jobPageBack()
 				return nil
 			case 'r', 'R':
-				a.refreshJobs(table, projectID, pipelineID)
+				a.refreshJobs(list)
+				return nil
+			case 'g':
+				a.toggleJobView(list, jobViewGraph)
+				return nil
+			case 'd':
+				a.toggleJobView(list, jobViewDAG)
+				return nil
+			case 't':
+				a.handleJobAction(list, "retry")
+				return nil
+			case 'x':
+				a.handleJobAction(list, "cancel")
+				return nil
+			case 'p':
+				a.handleJobAction(list, "play")
 				return nil
 			}
 		case tcell.KeyEsc:
-			a.pages.SwitchToPage(PagePipeline)
+			a.jobPageBack()
 			return nil
+		case tcell.KeyLeft, tcell.KeyRight, tcell.KeyUp, tcell.KeyDown:
+			if list.view != jobViewList {
+				moveGraphSelection(table, event.Key())
+				return nil
+			}
 		}
 		return event
 	})
 
 	table.SetSelectedFunc(func(row, column int) {
-		cell := table.GetCell(row, column)
-		if cell != nil {
-			ref := cell.GetReference()
-			if job, ok := ref.(gitlab.Job); ok {
-				a.showJobDetailsModal(job, projectID, pipelineID)
-			}
+		job, ok := list.selectedJob()
+		switch {
+		case !ok:
+		case job.Bridge:
+			a.showDownstreamPipeline(list, job)
+		default:
+			a.showJobDetails(job, proj, pipelineID)
+		}
+	})
+	table.SetSelectionChangedFunc(func(row, column int) {
+		if list.view == jobViewDAG {
+			a.updateJobInfo(list)
 		}
 	})
 
+	headerHeight := 4
+	if list.trail != "" {
+		headerHeight = 5
+	}
 	container.
-		AddItem(header, 4, 0, false).
-		AddItem(table, 0, 1, true)
+		AddItem(header, headerHeight, 0, false).
+		AddItem(table, 0, 1, true).
+		AddItem(list.info, 0, 0, false)
 
-	return container
-}
+	a.poll(PageJob, func(done func(active bool)) {
+		a.pollJobs(list, done)
+	})
 
-func (a *App) showJobDetailsModal(job gitlab.Job, projectID int, pipelineID int) {
-	panic("unimplemented")
+	return container
 }
 
-func (a *App) createJobHeader(projectID int, pipelineID int) *tview.TextView {
+func (a *App) createJobHeader(list *jobList) *tview.TextView {
 	header := tview.NewTextView().
 		SetRegions(true).
 		SetTextAlign(tview.AlignCenter)
 	header.SetBackgroundColor(ColorBlue)
 
-	headerText := fmt.Sprintf(
-		"⚙️ [::bu]Jobs for pipeline #%d[::-]\n[::d]Projekt-ID: %d | last updated: %s[::-]",
-		pipelineID,
-		projectID,
-		time.Now().Format("15:04:05"),
-	)
-
-	header.SetText(headerText)
+	header.SetText(jobHeaderText(list))
 
 	header.SetBorder(true)
 	header.SetBorderColor(ColorOrange)
@@ -81,29 +156,59 @@ func (a *App) createJobHeader(projectID int, pipelineID int) *tview.TextView {
 	return header
 }
 
-func (a *App) styleJobTable(table *tview.Table, pipelineID int) {
+func jobHeaderText(list *jobList) string {
+	text := fmt.Sprintf(
+		"⚙️ [::bu]Jobs for pipeline #%d[::-]\n[::d]%s (%s) | Projekt-ID: %d | last updated: %s[::-]",
+		list.pipelineID,
+		list.proj.Name,
+		list.proj.Instance,
+		list.proj.ID,
+		time.Now().Format("15:04:05"),
+	)
+	if list.trail != "" {
+		text += "\n[::d]" + tview.Escape(list.trail) + " (b: zurück)[::-]"
+	}
+	return text
+}
+
+func (a *App) styleJobTable(list *jobList) {
+	table := list.table
 	table.SetBorder(true)
 	table.SetBorderColor(ColorOrange)
-	table.SetTitle(fmt.Sprintf(" 📋 Jobs for Pipeline #%d ", pipelineID))
+	table.SetTitle(jobTableTitle(list))
 	table.SetTitleAlign(tview.AlignLeft)
 	table.SetTitleColor(ColorPink)
 	table.SetBackgroundColor(ColorBlue)
 }
 
-func (a *App) handleJobClick(projectID string, pipelineID int) *tview.Table {
+func jobTableTitle(list *jobList) string {
+	switch list.view {
+	case jobViewGraph:
+		return fmt.Sprintf(" 📊 Graph for Pipeline #%d (g: Liste) ", list.pipelineID)
+	case jobViewDAG:
+		return fmt.Sprintf(" 🔗 Needs for Pipeline #%d (d: Liste) ", list.pipelineID)
+	}
+	return fmt.Sprintf(" 📋 Jobs for Pipeline #%d (g: Graph, d: Needs) ", list.pipelineID)
+}
+
+func (a *App) handleJobClick(proj config.GitLabProject, pipelineID, selectedID int) *jobList {
+	client, _ := a.clientFor(proj)
+
 	table := tview.NewTable().
 		SetBorders(true).
 		SetSelectable(true, false)
 
 	table.SetBackgroundColor(ColorBlue)
 
+	list := &jobList{table: table, proj: proj, pipelineID: pipelineID}
+
 	loadingCell := tview.NewTableCell("⏳ Lade Jobs...").
 		SetTextColor(tcell.ColorWhite).
 		SetSelectable(false)
 	table.SetCell(0, 0, loadingCell)
 
 	go func() {
-		jobs, err := gitlab.GetJobDetails(projectID, pipelineID, a.token)
+		jobs, err := client.GetJobDetails(fmt.Sprint(proj.ID), pipelineID)
 
 		a.app.QueueUpdateDraw(func() {
 			table.Clear()
@@ -116,20 +221,11 @@ func (a *App) handleJobClick(projectID string, pipelineID int) *tview.Table {
 				return
 			}
 
-			headerCell := tview.NewTableCell(fmt.Sprintf("Jobs (%d)", len(jobs))).
-				SetTextColor(tcell.ColorWhite).
-				SetSelectable(false).
-				SetAttributes(tcell.AttrBold)
-			table.SetCell(0, 0, headerCell)
-
-			for i, job := range jobs {
-				cell := a.createJobTableCell(job)
-				table.SetCell(i+1, 0, cell)
-			}
+			a.renderJobs(list, jobs, selectedID)
 		})
 	}()
 
-	return table
+	return list
 }
 
 func (a *App) createJobTableCell(job gitlab.Job) *tview.TableCell {
@@ -143,6 +239,9 @@ func (a *App) createJobTableCell(job gitlab.Job) *tview.TableCell {
 	if job.Stage != "" {
 		cellText += fmt.Sprintf(" [darkgray][%s][white]", job.Stage)
 	}
+	if label := downstreamLabel(job); label != "" {
+		cellText += fmt.Sprintf(" [gray]%s[white]", label)
+	}
 
 	cell := tview.NewTableCell(cellText).
 		SetReference(job).
@@ -163,6 +262,9 @@ func (a *App) createJobTableCell(job gitlab.Job) *tview.TableCell {
 			if job.Stage != "" {
 				newText += fmt.Sprintf(" [darkgray][%s][white]", job.Stage)
 			}
+			if label := downstreamLabel(job); label != "" {
+				newText += fmt.Sprintf(" [gray]%s[white]", label)
+			}
 			cell.SetText(newText)
 		})
 	}(cell, job)
@@ -170,17 +272,22 @@ func (a *App) createJobTableCell(job gitlab.Job) *tview.TableCell {
 	return cell
 }
 
-func (a *App) refreshJobs(table *tview.Table, projectID int, pipelineID int) {
+func (a *App) refreshJobs(list *jobList) {
+	client, _ := a.clientFor(list.proj)
+	table := list.table
+
 	table.Clear()
 	loadingCell := tview.NewTableCell("⏳ Aktualisiere Jobs...").
 		SetTextColor(tcell.ColorWhite).
 		SetSelectable(false)
 	table.SetCell(0, 0, loadingCell)
 
+	withNeeds := list.view == jobViewDAG
+	withStages := list.view != jobViewList && list.stages == nil
 	go func() {
 		time.Sleep(300 * time.Millisecond)
 
-		jobs, err := gitlab.GetJobDetails(fmt.Sprint(projectID), pipelineID, a.token)
+		data, err := fetchJobs(client, list, withNeeds, withStages)
 
 		a.app.QueueUpdateDraw(func() {
 			table.Clear()
@@ -193,16 +300,157 @@ func (a *App) refreshJobs(table *tview.Table, projectID int, pipelineID int) {
 				return
 			}
 
-			headerCell := tview.NewTableCell(fmt.Sprintf("Jobs (%d)", len(jobs))).
-				SetTextColor(tcell.ColorWhite).
-				SetSelectable(false).
-				SetAttributes(tcell.AttrBold)
-			table.SetCell(0, 0, headerCell)
+			list.setJobData(data)
+			a.setJobRows(list, data.jobs)
+			list.header.SetText(jobHeaderText(list))
+		})
+	}()
+}
 
-			for i, job := range jobs {
-				cell := a.createJobTableCell(job)
-				table.SetCell(i+1, 0, cell)
+// pollJobs reloads the jobs of the pipeline and updates the rows in place.
+func (a *App) pollJobs(list *jobList, done func(active bool)) {
+	client, _ := a.clientFor(list.proj)
+
+	withNeeds := list.view == jobViewDAG
+	withStages := list.view != jobViewList && list.stages == nil
+	go func() {
+		data, err := fetchJobs(client, list, withNeeds, withStages)
+
+		a.app.QueueUpdateDraw(func() {
+			if err != nil {
+				done(false)
+				return
 			}
+			list.setJobData(data)
+			active := a.setJobRows(list, data.jobs)
+			list.header.SetText(jobHeaderText(list))
+			done(active)
 		})
 	}()
 }
+
+// jobData is the result of reloading the job page.
+type jobData struct {
+	jobs     gitlab.Jobs
+	stages   []string
+	needsErr error
+}
+
+// fetchJobs loads the jobs of the pipeline, with their needs if withNeeds
+// and the stage order if withStages is set. If only the needs fail to
+// load, the jobs are returned together with needsErr. Without the stage
+// order the graph falls back to the job IDs.
+func fetchJobs(client *gitlab.Client, list *jobList, withNeeds, withStages bool) (jobData, error) {
+	projectID := fmt.Sprint(list.proj.ID)
+	jobs, err := client.GetJobDetails(projectID, list.pipelineID)
+	if err != nil {
+		return jobData{}, err
+	}
+	data := jobData{jobs: jobs}
+	if withStages {
+		data.stages, _ = client.GetPipelineStages(projectID, list.pipelineID)
+	}
+	if withNeeds {
+		data.needsErr = client.AddJobNeeds(projectID, list.pipelineID, jobs)
+	}
+	return data, nil
+}
+
+// setJobData keeps the stage order and needs error of a reload.
+func (l *jobList) setJobData(data jobData) {
+	l.needsErr = data.needsErr
+	if data.stages != nil {
+		l.stages = data.stages
+	}
+}
+
+// selectedJob returns the job under the cursor.
+func (l *jobList) selectedJob() (gitlab.Job, bool) {
+	row, column := l.table.GetSelection()
+	if l.view == jobViewList {
+		column = 0
+	}
+	job, ok := l.table.GetCell(row, column).GetReference().(gitlab.Job)
+	return job, ok
+}
+
+// toggleJobView switches between the job list and view. The needs of the
+// jobs are only loaded while the DAG view is shown, the stage order once
+// for either graph.
+func (a *App) toggleJobView(list *jobList, view jobView) {
+	selected, _ := list.selectedJob()
+	if list.view == view {
+		view = jobViewList
+	}
+	list.view = view
+	list.table.SetTitle(jobTableTitle(list))
+
+	infoHeight := 0
+	if view == jobViewDAG {
+		infoHeight = 5
+	}
+	list.container.ResizeItem(list.info, infoHeight, 0)
+
+	if list.jobs == nil {
+		return
+	}
+	a.renderJobs(list, list.jobs, selected.ID)
+	if view == jobViewDAG || (view != jobViewList && list.stages == nil) {
+		a.pollJobs(list, func(bool) {})
+	}
+}
+
+// setJobRows replaces the table content with jobs in the current view,
+// keeps the selected job selected and reports whether any job is still
+// active.
+func (a *App) setJobRows(list *jobList, jobs gitlab.Jobs) bool {
+	selectedID := 0
+	if job, ok := list.selectedJob(); ok {
+		selectedID = job.ID
+	}
+	return a.renderJobs(list, jobs, selectedID)
+}
+
+// renderJobs draws jobs and selects the job with selectedID.
+func (a *App) renderJobs(list *jobList, jobs gitlab.Jobs, selectedID int) bool {
+	table := list.table
+	list.jobs = jobs
+	table.Clear()
+
+	switch list.view {
+	case jobViewGraph:
+		table.SetSelectable(true, true)
+		a.setJobGraph(table, jobs, list.stages, selectedID)
+	case jobViewDAG:
+		table.SetSelectable(true, true)
+		a.setJobDAG(table, jobs, list.stages, selectedID)
+		a.updateJobInfo(list)
+	default:
+		table.SetSelectable(true, false)
+		a.setJobListRows(table, jobs, selectedID)
+	}
+
+	active := false
+	for _, job := range jobs {
+		if gitlab.IsActiveStatus(job.Status) {
+			active = true
+		}
+	}
+	return active
+}
+
+func (a *App) setJobListRows(table *tview.Table, jobs gitlab.Jobs, selectedID int) {
+	headerCell := tview.NewTableCell(fmt.Sprintf("Jobs (%d)", len(jobs))).
+		SetTextColor(tcell.ColorWhite).
+		SetSelectable(false).
+		SetAttributes(tcell.AttrBold)
+	table.SetCell(0, 0, headerCell)
+
+	for i, job := range jobs {
+		cell := a.createJobTableCell(job)
+		table.SetCell(i+1, 0, cell)
+		if job.ID == selectedID {
+			table.Select(i+1, 0)
+		}
+	}
+}
@@ -0,0 +1,264 @@
+package ui
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Youdontknowme720/Cimonv2/gitlab"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// jobDAG holds the dependencies between the jobs of a pipeline. Jobs with
+// needs depend on the jobs they need, all others on every job of the
+// previous stages.
+type jobDAG struct {
+	jobs     gitlab.Jobs
+	upstream map[int][]gitlab.Job
+	depth    map[int]int
+	stage    map[string]int
+}
+
+// parallelSuffixRe matches the suffix GitLab adds to parallel and matrix
+// jobs, e.g. "test 1/3" or "test: [linux, amd64]". needs refer to them by
+// the name without the suffix.
+var parallelSuffixRe = regexp.MustCompile(`( \d+/\d+|: \[.*\])$`)
+
+// newJobDAG builds the dependencies of jobs. stageOrder is the stage order
+// of the pipeline, see groupJobsByStage.
+func newJobDAG(jobs gitlab.Jobs, stageOrder []string) *jobDAG {
+	d := &jobDAG{
+		jobs:     jobs,
+		upstream: make(map[int][]gitlab.Job, len(jobs)),
+		depth:    make(map[int]int, len(jobs)),
+		stage:    make(map[string]int),
+	}
+	for i, stage := range groupJobsByStage(jobs, stageOrder) {
+		d.stage[stage.name] = i
+	}
+
+	byName := make(map[string][]gitlab.Job)
+	for _, job := range jobs {
+		byName[job.Name] = append(byName[job.Name], job)
+		if base := parallelSuffixRe.ReplaceAllString(job.Name, ""); base != job.Name {
+			byName[base] = append(byName[base], job)
+		}
+	}
+
+	for _, job := range jobs {
+		var upstream []gitlab.Job
+		if job.SchedulingType == "dag" {
+			for _, need := range job.Needs {
+				upstream = append(upstream, byName[need]...)
+			}
+		} else {
+			for _, other := range jobs {
+				if d.stage[other.Stage] < d.stage[job.Stage] {
+					upstream = append(upstream, other)
+				}
+			}
+		}
+		d.upstream[job.ID] = upstream
+	}
+
+	for _, job := range jobs {
+		d.jobDepth(job, make(map[int]bool))
+	}
+	return d
+}
+
+// jobDepth returns the length of the longest chain of upstream jobs.
+func (d *jobDAG) jobDepth(job gitlab.Job, visiting map[int]bool) int {
+	if depth, ok := d.depth[job.ID]; ok {
+		return depth
+	}
+	if visiting[job.ID] {
+		return 0
+	}
+	visiting[job.ID] = true
+
+	depth := 0
+	for _, up := range d.upstream[job.ID] {
+		depth = max(depth, d.jobDepth(up, visiting)+1)
+	}
+	d.depth[job.ID] = depth
+	return depth
+}
+
+// blocker returns the upstream job that job is waiting for, or the one
+// that finished last before job could start.
+func (d *jobDAG) blocker(job gitlab.Job) (gitlab.Job, bool) {
+	var (
+		found  gitlab.Job
+		latest time.Time
+		ok     bool
+	)
+	for _, up := range d.upstream[job.ID] {
+		if gitlab.IsActiveStatus(up.Status) {
+			if !ok || up.Status == "running" {
+				found, ok = up, true
+			}
+			latest = time.Now().Add(time.Hour)
+			continue
+		}
+		if finished, err := time.Parse(time.RFC3339, up.FinishedAt); err == nil && finished.After(latest) {
+			found, latest, ok = up, finished, true
+		}
+	}
+	return found, ok
+}
+
+// criticalPath returns the chain of jobs that determined the duration of
+// the pipeline: the job that finished last and, one by one, the upstream
+// job it had to wait for.
+func (d *jobDAG) criticalPath() []gitlab.Job {
+	var (
+		last   gitlab.Job
+		latest time.Time
+	)
+	for _, job := range d.jobs {
+		if end, ok := jobEnd(job); ok && end.After(latest) {
+			last, latest = job, end
+		}
+	}
+	if latest.IsZero() {
+		return nil
+	}
+
+	path := []gitlab.Job{last}
+	seen := map[int]bool{last.ID: true}
+	for {
+		up, ok := d.blocker(path[len(path)-1])
+		if !ok || seen[up.ID] {
+			break
+		}
+		if _, started := jobEnd(up); !started {
+			break
+		}
+		seen[up.ID] = true
+		path = append(path, up)
+	}
+	slices.Reverse(path)
+	return path
+}
+
+// jobEnd returns when a job finished, or now for running jobs. Jobs that
+// never started have no end.
+func jobEnd(job gitlab.Job) (time.Time, bool) {
+	if finished, err := time.Parse(time.RFC3339, job.FinishedAt); err == nil {
+		return finished, true
+	}
+	if job.Status == "running" {
+		return time.Now(), true
+	}
+	return time.Time{}, false
+}
+
+// setJobDAG lays out the jobs in columns by the length of their chain of
+// needs, so each job is to the right of everything it waits for. Jobs on
+// the critical path are highlighted.
+func (a *App) setJobDAG(table *tview.Table, jobs gitlab.Jobs, stageOrder []string, selectedID int) {
+	dag := newJobDAG(jobs, stageOrder)
+	critical := make(map[int]bool)
+	for _, job := range dag.criticalPath() {
+		critical[job.ID] = true
+	}
+
+	var columns [][]gitlab.Job
+	for _, job := range jobs {
+		depth := dag.depth[job.ID]
+		for len(columns) <= depth {
+			columns = append(columns, nil)
+		}
+		columns[depth] = append(columns[depth], job)
+	}
+
+	rows := 0
+	selectedRow, selectedColumn := 1, 0
+	for col, column := range columns {
+		slices.SortFunc(column, func(x, y gitlab.Job) int {
+			return cmp.Or(cmp.Compare(dag.stage[x.Stage], dag.stage[y.Stage]), cmp.Compare(x.Name, y.Name))
+		})
+		table.SetCell(0, col, tview.NewTableCell(fmt.Sprintf("Ebene %d", col+1)).
+			SetTextColor(ColorAccent).
+			SetAttributes(tcell.AttrBold).
+			SetSelectable(false))
+
+		for i, job := range column {
+			cell := createJobGraphCell(job)
+			if critical[job.ID] {
+				cell.SetText("🔥 " + cell.Text).
+					SetBackgroundColor(ColorPurple).
+					SetAttributes(tcell.AttrBold)
+			}
+			table.SetCell(i+1, col, cell)
+			if job.ID == selectedID {
+				selectedRow, selectedColumn = i+1, col
+			}
+		}
+		rows = max(rows, len(column))
+	}
+
+	for row := 1; row <= rows; row++ {
+		for col, column := range columns {
+			if row > len(column) {
+				table.SetCell(row, col, tview.NewTableCell("").SetSelectable(false))
+			}
+		}
+	}
+
+	if len(columns) > 0 {
+		table.Select(selectedRow, selectedColumn)
+	}
+}
+
+// updateJobInfo shows what the selected job waits for and the critical
+// path of the pipeline below the DAG.
+func (a *App) updateJobInfo(list *jobList) {
+	if list.needsErr != nil {
+		list.info.SetText("[red]❌ " + tview.Escape(list.needsErr.Error()) + "[-]\n[gray]Es wird die Stage-Reihenfolge verwendet.[-]")
+		return
+	}
+
+	dag := newJobDAG(list.jobs, list.stages)
+	var lines []string
+
+	if job, ok := list.selectedJob(); ok {
+		if job.SchedulingType == "dag" {
+			needs := "keine, startet sofort"
+			if len(job.Needs) > 0 {
+				needs = strings.Join(job.Needs, ", ")
+			}
+			lines = append(lines, fmt.Sprintf("[::b]%s[::-] needs: %s", tview.Escape(job.Name), tview.Escape(needs)))
+		} else {
+			lines = append(lines, fmt.Sprintf("[::b]%s[::-] wartet auf alle vorherigen Stages", tview.Escape(job.Name)))
+		}
+
+		if up, ok := dag.blocker(job); ok {
+			label := "Gestartet nach"
+			if job.StartedAt == "" {
+				label = "Blockiert durch"
+			}
+			lines = append(lines, fmt.Sprintf("%s: %s %s", label, gitlab.StatusEmoji(up.Status), tview.Escape(up.Name)))
+		}
+	}
+
+	if path := dag.criticalPath(); len(path) > 0 {
+		names := make([]string, len(path))
+		for i, job := range path {
+			names[i] = job.Name
+		}
+		text := "🔥 Kritischer Pfad: " + tview.Escape(strings.Join(names, " → "))
+		start, err := time.Parse(time.RFC3339, path[0].StartedAt)
+		if end, ok := jobEnd(path[len(path)-1]); ok && err == nil {
+			text += fmt.Sprintf(" (%s)", formatDuration(end.Sub(start)))
+		}
+		lines = append(lines, text)
+	}
+
+	list.info.SetText(strings.Join(lines, "\n"))
+}
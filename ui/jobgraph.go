@@ -0,0 +1,177 @@
+package ui
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/Youdontknowme720/Cimonv2/gitlab"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// jobStage is a column of the pipeline graph.
+type jobStage struct {
+	name string
+	jobs []gitlab.Job
+}
+
+// groupJobsByStage returns the stages in the pipeline order with their
+// jobs sorted by name. order is the stage order from the API; stages it
+// does not list, e.g. because it could not be loaded, follow by their
+// lowest job ID.
+func groupJobsByStage(jobs gitlab.Jobs, order []string) []jobStage {
+	firstID := make(map[string]int)
+	byStage := make(map[string][]gitlab.Job)
+	for _, job := range jobs {
+		if id, ok := firstID[job.Stage]; !ok || job.ID < id {
+			firstID[job.Stage] = job.ID
+		}
+		byStage[job.Stage] = append(byStage[job.Stage], job)
+	}
+
+	position := func(name string) int {
+		if i := slices.Index(order, name); i >= 0 {
+			return i
+		}
+		return len(order)
+	}
+
+	stages := make([]jobStage, 0, len(byStage))
+	for name, stageJobs := range byStage {
+		slices.SortFunc(stageJobs, func(x, y gitlab.Job) int {
+			return cmp.Or(cmp.Compare(x.Name, y.Name), cmp.Compare(x.ID, y.ID))
+		})
+		stages = append(stages, jobStage{name: name, jobs: stageJobs})
+	}
+	slices.SortFunc(stages, func(x, y jobStage) int {
+		return cmp.Or(cmp.Compare(position(x.name), position(y.name)), cmp.Compare(firstID[x.name], firstID[y.name]))
+	})
+	return stages
+}
+
+// setJobGraph lays out the jobs in one column per stage, like the pipeline
+// graph in GitLab, and selects the job with selectedID or the first job.
+func (a *App) setJobGraph(table *tview.Table, jobs gitlab.Jobs, stageOrder []string, selectedID int) {
+	stages := groupJobsByStage(jobs, stageOrder)
+
+	selectedRow, selectedColumn := 1, 0
+	for col, stage := range stages {
+		table.SetCell(0, col, tview.NewTableCell(fmt.Sprintf("%s %s", gitlab.StatusEmoji(stageStatus(stage.jobs)), stage.name)).
+			SetTextColor(ColorAccent).
+			SetAttributes(tcell.AttrBold).
+			SetSelectable(false))
+
+		for i, job := range stage.jobs {
+			table.SetCell(i+1, col, createJobGraphCell(job))
+			if job.ID == selectedID {
+				selectedRow, selectedColumn = i+1, col
+			}
+		}
+	}
+
+	// Fill the gaps below short stages, so the borders are drawn through.
+	for row := 1; row < table.GetRowCount(); row++ {
+		for col := range stages {
+			if row > len(stages[col].jobs) {
+				table.SetCell(row, col, tview.NewTableCell("").SetSelectable(false))
+			}
+		}
+	}
+
+	if len(stages) > 0 {
+		table.Select(selectedRow, selectedColumn)
+	}
+}
+
+func createJobGraphCell(job gitlab.Job) *tview.TableCell {
+	text := fmt.Sprintf("%s %s", gitlab.StatusEmoji(job.Status), job.Name)
+	if job.Duration > 0 {
+		text += fmt.Sprintf(" (%v)", (time.Duration(job.Duration) * time.Second).Round(time.Second))
+	}
+	if label := downstreamLabel(job); label != "" {
+		text += " " + label
+	}
+
+	return tview.NewTableCell(text).
+		SetReference(job).
+		SetTextColor(jobStatusColor(job.Status)).
+		SetSelectable(true).
+		SetSelectedStyle(tcell.StyleDefault.
+			Background(jobStatusColor(job.Status)).
+			Foreground(ColorBlue).
+			Bold(true))
+}
+
+// jobStatusColor returns the colour GitLab uses for a job status.
+func jobStatusColor(status string) tcell.Color {
+	switch status {
+	case "success":
+		return tcell.ColorLimeGreen
+	case "failed":
+		return tcell.ColorRed
+	case "running":
+		return tcell.ColorDodgerBlue
+	case "pending", "created", "waiting_for_resource", "preparing", "scheduled":
+		return ColorWarning
+	case "manual":
+		return tcell.ColorLightGray
+	default:
+		return tcell.ColorGray
+	}
+}
+
+// stageStatus summarises the jobs of a stage the way GitLab does: failures
+// and running jobs win over everything else.
+func stageStatus(jobs []gitlab.Job) string {
+	for _, status := range []string{"failed", "running", "pending", "manual", "canceled", "success"} {
+		for _, job := range jobs {
+			if job.Status == status {
+				return status
+			}
+		}
+	}
+	return "skipped"
+}
+
+// moveGraphSelection moves the selection of the graph table by one job.
+// Left and right jump to the neighbouring stage, staying on the same row
+// or the last job of a shorter stage.
+func moveGraphSelection(table *tview.Table, key tcell.Key) {
+	row, col := table.GetSelection()
+	// GetCell returns an empty cell outside of the table, so look for jobs.
+	selectable := func(r, c int) bool {
+		_, ok := table.GetCell(r, c).GetReference().(gitlab.Job)
+		return ok
+	}
+	lastRow := func(c int) int {
+		r := table.GetRowCount() - 1
+		for r > 1 && !selectable(r, c) {
+			r--
+		}
+		return r
+	}
+
+	switch key {
+	case tcell.KeyUp:
+		if row > 1 {
+			row--
+		}
+	case tcell.KeyDown:
+		if selectable(row+1, col) {
+			row++
+		}
+	case tcell.KeyLeft:
+		if col > 0 {
+			col--
+			row = min(row, lastRow(col))
+		}
+	case tcell.KeyRight:
+		if col < table.GetColumnCount()-1 {
+			col++
+			row = min(row, lastRow(col))
+		}
+	}
+	table.Select(row, col)
+}
@@ -0,0 +1,493 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Youdontknowme720/Cimonv2/config"
+	"github.com/Youdontknowme720/Cimonv2/gitlab"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// showJobDetails opens a full-screen page with the job's metadata and its
+// trace.
+func (a *App) showJobDetails(job gitlab.Job, proj config.GitLabProject, pipelineID int) {
+	client, _ := a.clientFor(proj)
+	projectID := fmt.Sprint(proj.ID)
+
+	container := tview.NewFlex().SetDirection(tview.FlexRow)
+
+	header := a.createJobDetailsHeader(job, pipelineID)
+	logs := newLogView()
+	logs.view.SetTitle(fmt.Sprintf(" 📜 Log: %s ", job.Name))
+
+	search := tview.NewInputField().
+		SetLabel("🔍 Suche: ").
+		SetFieldBackgroundColor(ColorOrange).
+		SetFieldTextColor(tcell.ColorBlack).
+		SetLabelColor(tcell.ColorWhite)
+	search.SetBackgroundColor(ColorBlue)
+
+	// follow keeps the view scrolled to the end while new output arrives.
+	// It is switched off as soon as the user scrolls up.
+	follow := true
+	var stop chan struct{}
+
+	updateTitle := func() {
+		title := fmt.Sprintf(" 📜 Log: %s ", job.Name)
+		if stop != nil && gitlab.IsActiveStatus(job.Status) {
+			if follow {
+				title += "[FOLLOW] "
+			} else {
+				title += "[PAUSED – f to follow] "
+			}
+		}
+		// Keep the brackets of the labels and job name from being read as
+		// style tags.
+		logs.view.SetTitle(tview.Escape(title))
+	}
+
+	loadLog := func() {
+		if stop != nil {
+			close(stop)
+		}
+		stop = make(chan struct{})
+		follow = true
+		updateTitle()
+		logs.view.SetText("⏳ Lade Log...")
+		a.tailJobLog(client.TailJobLog(projectID, job), stop, func(trace string, current gitlab.Job) {
+			if current.Status != job.Status {
+				job = current
+				header.SetText(jobDetailsText(job, pipelineID))
+			}
+			updateTitle()
+			logs.SetTrace(gitlab.ParseTrace(trace))
+			if follow {
+				logs.view.ScrollToEnd()
+			}
+		}, func(err error) {
+			logs.view.SetText("[red]❌ Fehler beim Laden des Logs: " + tview.Escape(err.Error()))
+		})
+	}
+
+	setFollow := func(on bool) {
+		follow = on
+		if on {
+			logs.view.ScrollToEnd()
+		}
+		updateTitle()
+	}
+
+	back := func() {
+		close(stop)
+		a.pages.RemovePage(PageJobLog)
+		a.pages.SwitchToPage(PageJob)
+	}
+
+	search.SetDoneFunc(func(key tcell.Key) {
+		switch key {
+		case tcell.KeyEnter:
+			if search.GetText() != "" {
+				setFollow(false)
+			}
+			if n := logs.Search(search.GetText()); n == 0 && search.GetText() != "" {
+				a.showNotification("Keine Treffer", ColorWarning)
+			}
+		case tcell.KeyEsc:
+			search.SetText("")
+			logs.Search("")
+		}
+		a.app.SetFocus(logs.view)
+	})
+
+	logs.view.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyRune:
+			switch event.Rune() {
+			case 'b', 'B':
+				back()
+				return nil
+			case '/':
+				a.app.SetFocus(search)
+				return nil
+			case 'n':
+				setFollow(false)
+				logs.Jump(1)
+				return nil
+			case 'N':
+				setFollow(false)
+				logs.Jump(-1)
+				return nil
+			case 'f', 'G':
+				setFollow(true)
+				return event
+			case 'k', 'g':
+				setFollow(false)
+				return event
+			case 'r', 'R':
+				loadLog()
+				return nil
+			case ' ':
+				logs.ToggleSection()
+				return nil
+			case 'e':
+				logs.SetAllCollapsed(false)
+				return nil
+			case 'c':
+				logs.SetAllCollapsed(true)
+				return nil
+			}
+		case tcell.KeyTab:
+			logs.FocusSection(1)
+			return nil
+		case tcell.KeyBacktab:
+			logs.FocusSection(-1)
+			return nil
+		case tcell.KeyEnter:
+			logs.ToggleSection()
+			return nil
+		case tcell.KeyEsc:
+			back()
+			return nil
+		case tcell.KeyUp, tcell.KeyPgUp, tcell.KeyHome:
+			setFollow(false)
+		case tcell.KeyEnd:
+			setFollow(true)
+		}
+		return event
+	})
+
+	container.
+		AddItem(header, 6, 0, false).
+		AddItem(logs.view, 0, 1, true).
+		AddItem(search, 1, 0, false)
+
+	a.pages.AddPage(PageJobLog, container, true, true)
+	a.pages.SwitchToPage(PageJobLog)
+	loadLog()
+}
+
+func (a *App) createJobDetailsHeader(job gitlab.Job, pipelineID int) *tview.TextView {
+	header := tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignLeft)
+	header.SetBackgroundColor(ColorBlue)
+
+	header.SetText(jobDetailsText(job, pipelineID))
+
+	header.SetBorder(true)
+	header.SetBorderColor(ColorOrange)
+	header.SetTitle(fmt.Sprintf(" 🔨 Job #%d ", job.ID))
+	header.SetTitleAlign(tview.AlignCenter)
+	header.SetTitleColor(ColorPink)
+
+	return header
+}
+
+func jobDetailsText(job gitlab.Job, pipelineID int) string {
+	runner := "-"
+	if job.Runner != nil {
+		runner = fmt.Sprintf("%s (#%d)", job.Runner.Description, job.Runner.ID)
+	}
+
+	duration := "-"
+	if job.Duration > 0 {
+		duration = (time.Duration(job.Duration) * time.Second).Round(time.Second).String()
+	}
+
+	return fmt.Sprintf(
+		"%s [::b]%s[::-] (%s) | Pipeline #%d | Stage: %s | Ref: %s\n"+
+			"Dauer: %s | Runner: %s\n"+
+			"Gestartet: %s | Beendet: %s\n"+
+			"[::u]%s[::-]",
+		gitlab.StatusEmoji(job.Status), tview.Escape(job.Name), job.Status, pipelineID,
+		tview.Escape(job.Stage), tview.Escape(job.Ref),
+		duration, tview.Escape(runner),
+		valueOrDash(job.StartedAt), valueOrDash(job.FinishedAt),
+		job.WebURL,
+	)
+}
+
+// logTailInterval is the delay between two fetches of a running job's log.
+const logTailInterval = 2 * time.Second
+
+// tailJobLog polls tail until the job has finished or stop is closed. After
+// every fetch update is called on the UI goroutine with the whole trace read
+// so far.
+func (a *App) tailJobLog(tail *gitlab.LogTail, stop chan struct{}, update func(trace string, job gitlab.Job), fail func(err error)) {
+	stopped := func() bool {
+		select {
+		case <-stop:
+			return true
+		default:
+			return false
+		}
+	}
+
+	go func() {
+		var trace strings.Builder
+		for {
+			chunk, err := tail.Fetch()
+			trace.WriteString(chunk)
+			text, job := trace.String(), tail.Job()
+
+			a.app.QueueUpdateDraw(func() {
+				if stopped() {
+					return
+				}
+				if err != nil {
+					fail(err)
+					return
+				}
+				update(text, job)
+			})
+
+			if err != nil || tail.Done() {
+				return
+			}
+			select {
+			case <-stop:
+				return
+			case <-time.After(logTailInterval):
+			}
+		}
+	}()
+}
+
+func valueOrDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// logLine is one line of a flattened trace.
+type logLine struct {
+	line    gitlab.TraceLine
+	section *gitlab.TraceSection
+	parents []*gitlab.TraceSection
+}
+
+// logView is a scrollable text view for job traces. Sections can be folded
+// and the log can be searched, jumping between matches.
+type logView struct {
+	view      *tview.TextView
+	lines     []logLine
+	collapsed map[string]bool
+	visible   []int
+	focused   string
+	query     string
+	matches   []int
+	current   int
+}
+
+func newLogView() *logView {
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetRegions(true).
+		SetScrollable(true).
+		SetWrap(false)
+	view.SetBackgroundColor(tcell.ColorBlack)
+	view.SetBorder(true).
+		SetBorderColor(ColorOrange).
+		SetTitleAlign(tview.AlignLeft).
+		SetTitleColor(ColorPink)
+
+	return &logView{view: view, collapsed: make(map[string]bool)}
+}
+
+// SetTrace replaces the log content. Fold state of sections that were
+// already shown is kept, new sections start folded if GitLab says so.
+func (l *logView) SetTrace(trace *gitlab.Trace) {
+	l.lines = l.lines[:0]
+	l.flatten(trace.Items, nil)
+	l.render()
+}
+
+func (l *logView) flatten(items []gitlab.TraceItem, parents []*gitlab.TraceSection) {
+	for _, item := range items {
+		if item.Line != nil {
+			l.lines = append(l.lines, logLine{line: *item.Line, parents: parents})
+			continue
+		}
+
+		section := item.Section
+		if _, known := l.collapsed[section.Name]; !known {
+			l.collapsed[section.Name] = section.Collapsed
+		}
+		l.lines = append(l.lines, logLine{line: section.Header, section: section, parents: parents})
+
+		nested := make([]*gitlab.TraceSection, len(parents), len(parents)+1)
+		copy(nested, parents)
+		l.flatten(section.Items, append(nested, section))
+	}
+}
+
+// Search highlights all lines containing query (case-insensitive), unfolds
+// the sections they are in and jumps to the first match. It returns the
+// number of matching lines.
+func (l *logView) Search(query string) int {
+	l.query = query
+	l.current = 0
+
+	if needle := strings.ToLower(query); needle != "" {
+		for _, line := range l.lines {
+			if strings.Contains(strings.ToLower(line.line.Plain), needle) {
+				for _, parent := range line.parents {
+					l.collapsed[parent.Name] = false
+				}
+			}
+		}
+	}
+
+	l.render()
+	if len(l.matches) > 0 {
+		l.view.ScrollToHighlight()
+	}
+	return len(l.matches)
+}
+
+// Jump moves the highlight delta matches forward or backward.
+func (l *logView) Jump(delta int) {
+	if len(l.matches) == 0 {
+		return
+	}
+	l.current = (l.current + delta + len(l.matches)) % len(l.matches)
+	l.view.Highlight(matchRegion(l.current))
+	l.view.ScrollToHighlight()
+}
+
+// FocusSection moves the section cursor delta section headers down or up,
+// starting at the top of the visible area if no section is focused yet.
+func (l *logView) FocusSection(delta int) {
+	var headers []int
+	pos := -1
+	for row, idx := range l.visible {
+		if section := l.lines[idx].section; section != nil {
+			if section.Name == l.focused {
+				pos = len(headers)
+			}
+			headers = append(headers, row)
+		}
+	}
+	if len(headers) == 0 {
+		return
+	}
+
+	if pos < 0 {
+		top, _ := l.view.GetScrollOffset()
+		pos = len(headers) - 1
+		for i, row := range headers {
+			if row >= top {
+				pos = i
+				break
+			}
+		}
+	} else {
+		pos = (pos + delta + len(headers)) % len(headers)
+	}
+
+	row := headers[pos]
+	l.focused = l.lines[l.visible[row]].section.Name
+	l.render()
+
+	_, _, _, height := l.view.GetInnerRect()
+	top, _ := l.view.GetScrollOffset()
+	if row < top || row >= top+height {
+		l.view.ScrollTo(max(row-height/3, 0), 0)
+	}
+}
+
+// ToggleSection folds or unfolds the focused section.
+func (l *logView) ToggleSection() {
+	if l.focused == "" {
+		l.FocusSection(0)
+	}
+	if l.focused == "" {
+		return
+	}
+	l.collapsed[l.focused] = !l.collapsed[l.focused]
+	l.render()
+}
+
+// SetAllCollapsed folds or unfolds every section.
+func (l *logView) SetAllCollapsed(collapsed bool) {
+	for name := range l.collapsed {
+		l.collapsed[name] = collapsed
+	}
+	l.render()
+}
+
+func (l *logView) isVisible(line logLine) bool {
+	for _, parent := range line.parents {
+		if l.collapsed[parent.Name] {
+			return false
+		}
+	}
+	return true
+}
+
+func (l *logView) render() {
+	needle := strings.ToLower(l.query)
+	l.visible = l.visible[:0]
+	l.matches = l.matches[:0]
+
+	var b strings.Builder
+	for idx, line := range l.lines {
+		if !l.isVisible(line) {
+			continue
+		}
+		l.visible = append(l.visible, idx)
+
+		text := line.line.Text
+		if line.section != nil {
+			text = l.sectionHeader(line)
+		}
+
+		if needle != "" && strings.Contains(strings.ToLower(line.line.Plain), needle) {
+			fmt.Fprintf(&b, `["%s"]%s[""]`, matchRegion(len(l.matches)), text)
+			l.matches = append(l.matches, len(l.visible)-1)
+		} else {
+			b.WriteString(text)
+		}
+		b.WriteByte('\n')
+	}
+
+	if l.current >= len(l.matches) {
+		l.current = 0
+	}
+
+	l.view.SetText(b.String())
+	if len(l.matches) > 0 {
+		l.view.Highlight(matchRegion(l.current))
+	} else {
+		l.view.Highlight()
+	}
+}
+
+func (l *logView) sectionHeader(line logLine) string {
+	section := line.section
+
+	arrow := "▼"
+	if l.collapsed[section.Name] {
+		arrow = "▶"
+	}
+
+	duration := "läuft"
+	if d := section.Duration(); d > 0 || !section.End.IsZero() {
+		duration = d.String()
+	}
+
+	header := fmt.Sprintf("%s%s %s[-:-:-] [gray](%s)[-]",
+		strings.Repeat("  ", len(line.parents)), arrow, line.line.Text, duration)
+	if section.Name == l.focused {
+		header = "[:darkslategray]" + header + "[:-]"
+	}
+	return header
+}
+
+func matchRegion(i int) string {
+	return fmt.Sprintf("match-%d", i)
+}
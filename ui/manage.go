@@ -0,0 +1,247 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Youdontknowme720/Cimonv2/config"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// showProjectSettings opens the screen to edit, remove and reorder the
+// configured projects.
+func (a *App) showProjectSettings() {
+	container := tview.NewFlex().SetDirection(tview.FlexRow)
+
+	header := tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignCenter).
+		SetText("⚙️ [::bu]Projekte verwalten[::-]\n" +
+			"[::d]e/Enter bearbeiten | d löschen | K/J verschieben | a hinzufügen | b zurück[::-]")
+	header.SetBackgroundColor(ColorBlue)
+	header.SetBorder(true).
+		SetBorderColor(ColorOrange).
+		SetTitle(" Settings ").
+		SetTitleAlign(tview.AlignCenter).
+		SetTitleColor(ColorPink)
+
+	table := newSelectableTable()
+	table.SetBorderColor(ColorOrange).
+		SetTitle(" 📁 Projekte ").
+		SetTitleAlign(tview.AlignLeft).
+		SetTitleColor(ColorPink).
+		SetBackgroundColor(ColorBlue)
+	table.SetSelectedStyle(tcell.StyleDefault.
+		Background(ColorBlue).
+		Foreground(ColorPink).
+		Bold(true))
+
+	a.setSettingsRows(table, config.GitLabProject{})
+
+	selected := func() (config.GitLabProject, bool) {
+		row, _ := table.GetSelection()
+		proj, ok := table.GetCell(row, 0).GetReference().(config.GitLabProject)
+		return proj, ok
+	}
+
+	move := func(delta int) {
+		proj, ok := selected()
+		if !ok {
+			return
+		}
+		if err := config.MoveProject(proj.Instance, proj.ID, delta); err != nil {
+			a.showNotification("❌ "+err.Error(), ColorDanger)
+			return
+		}
+		a.configWritten()
+		a.setSettingsRows(table, proj)
+	}
+
+	remove := func() {
+		proj, ok := selected()
+		if !ok {
+			return
+		}
+		a.confirm(fmt.Sprintf("Projekt %s (%s) aus der Konfiguration entfernen?", proj.Name, proj.Instance), func() {
+			if err := config.RemoveProject(proj.Instance, proj.ID); err != nil {
+				a.showNotification("❌ "+err.Error(), ColorDanger)
+				return
+			}
+			a.configWritten()
+			a.setSettingsRows(table, config.GitLabProject{})
+			a.showNotification(fmt.Sprintf("Projekt %s entfernt", proj.Name), ColorSuccess)
+		})
+	}
+
+	edit := func() {
+		if proj, ok := selected(); ok {
+			a.editProject(proj, func() { a.setSettingsRows(table, proj) })
+		}
+	}
+
+	table.SetSelectedFunc(func(row, column int) { edit() })
+
+	table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyRune:
+			switch event.Rune() {
+			case 'e':
+				edit()
+				return nil
+			case 'd':
+				remove()
+				return nil
+			case 'K':
+				move(-1)
+				return nil
+			case 'J':
+				move(1)
+				return nil
+			case 'a':
+				a.handleAddingProject()
+				return nil
+			case 'b', 'B':
+				a.reloadHome()
+				return nil
+			}
+		case tcell.KeyDelete:
+			remove()
+			return nil
+		case tcell.KeyUp, tcell.KeyDown:
+			if event.Modifiers()&tcell.ModShift != 0 {
+				if event.Key() == tcell.KeyUp {
+					move(-1)
+				} else {
+					move(1)
+				}
+				return nil
+			}
+		case tcell.KeyEsc:
+			a.reloadHome()
+			return nil
+		}
+		return event
+	})
+
+	container.
+		AddItem(header, 4, 0, false).
+		AddItem(table, 0, 1, true)
+
+	a.pages.AddPage(PageSettings, container, true, true)
+	a.pages.SwitchToPage(PageSettings)
+}
+
+// setSettingsRows re-reads the projects from the config and lists them per
+// instance. The row of selected is selected again if it still exists.
+func (a *App) setSettingsRows(table *tview.Table, selected config.GitLabProject) {
+	_, projects, err := config.GetProjectData()
+	if err != nil {
+		a.showNotification("❌ "+err.Error(), ColorDanger)
+	}
+	a.gitlabProjects = projects
+
+	row, _ := table.GetSelection()
+	table.Clear()
+
+	for col, title := range []string{"Name", "Pfad", "ID"} {
+		table.SetCell(0, col, tview.NewTableCell(title).
+			SetSelectable(false).
+			SetTextColor(ColorText).
+			SetAttributes(tcell.AttrBold))
+	}
+
+	r := 1
+	for _, group := range a.groupProjectsByInstance(projects) {
+		table.SetCell(r, 0, tview.NewTableCell(fmt.Sprintf("🌐 %s", group.title)).
+			SetSelectable(false).
+			SetTextColor(ColorAccent).
+			SetAttributes(tcell.AttrBold))
+		r++
+
+		for _, project := range group.projects {
+			table.SetCell(r, 0, tview.NewTableCell("  "+project.Name).
+				SetReference(project).
+				SetTextColor(ColorText))
+			table.SetCell(r, 1, tview.NewTableCell(valueOrDash(project.Path)).
+				SetTextColor(ColorText))
+			table.SetCell(r, 2, tview.NewTableCell(fmt.Sprint(project.ID)).
+				SetTextColor(ColorText))
+			if project.Instance == selected.Instance && project.ID == selected.ID {
+				row = r
+			}
+			r++
+		}
+	}
+
+	if r == 1 {
+		table.SetCell(1, 0, tview.NewTableCell("Keine Projekte konfiguriert – a zum Hinzufügen").
+			SetSelectable(false).
+			SetTextColor(ColorText))
+		return
+	}
+	table.Select(max(row, 1), 0)
+}
+
+// editProject shows a form to rename proj and calls done after saving.
+func (a *App) editProject(proj config.GitLabProject, done func()) {
+	form := tview.NewForm().
+		AddInputField("Name", proj.Name, 40, nil, nil).
+		AddTextView("Pfad", valueOrDash(proj.Path), 40, 1, false, false).
+		AddTextView("ID", fmt.Sprint(proj.ID), 40, 1, false, false).
+		AddTextView("Instance", proj.Instance, 40, 1, false, false)
+
+	form.SetBorder(true).
+		SetTitle(fmt.Sprintf(" ✏️ %s bearbeiten ", proj.Name)).
+		SetTitleAlign(tview.AlignCenter)
+	form.SetFieldBackgroundColor(ColorOrange)
+	form.SetFieldTextColor(tcell.ColorBlack)
+	form.SetLabelColor(tcell.ColorWhite)
+	form.SetTitleColor(ColorPink)
+	form.SetBorderColor(ColorOrange)
+	form.SetBackgroundColor(ColorBlue)
+	form.SetButtonBackgroundColor(ColorOrange)
+	form.SetButtonTextColor(tcell.ColorWhite)
+
+	nameField := form.GetFormItemByLabel("Name").(*tview.InputField)
+
+	abortFunc := func() {
+		a.pages.RemovePage(PageEditProj)
+		a.pages.SwitchToPage(PageSettings)
+	}
+
+	saveFunc := func() {
+		name := strings.TrimSpace(nameField.GetText())
+		if name == "" {
+			a.showNotification("Der Name darf nicht leer sein", ColorWarning)
+			return
+		}
+		updated := proj
+		updated.Name = name
+		if err := config.UpdateProject(proj.Instance, proj.ID, updated); err != nil {
+			a.showNotification("❌ "+err.Error(), ColorDanger)
+			return
+		}
+		a.configWritten()
+		abortFunc()
+		done()
+	}
+
+	form.AddButton("Save", saveFunc)
+	form.AddButton("Abort", abortFunc)
+
+	form.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyCtrlS:
+			saveFunc()
+			return nil
+		case event.Key() == tcell.KeyCtrlB || event.Key() == tcell.KeyEsc:
+			abortFunc()
+			return nil
+		}
+		return event
+	})
+
+	a.pages.AddPage(PageEditProj, form, true, true)
+	a.pages.SwitchToPage(PageEditProj)
+}
@@ -11,12 +11,16 @@ import (
 	"github.com/rivo/tview"
 )
 
-func (a *App) createPipelinePage(proj config.GitLabProject) tview.Primitive {
+func (a *App) createPipelinePage(proj config.GitLabProject, filter gitlab.PipelineFilter) tview.Primitive {
 	container := tview.NewFlex().SetDirection(tview.FlexRow)
 
-	header := a.createPipelineHeader(proj)
+	header := a.createPipelineHeader(proj, filter)
 
-	table := a.handlePipelineClick(fmt.Sprint(proj.ID))
+	list := a.handlePipelineClick(proj, filter)
+	list.header = header
+	list.container = container
+	list.filterBar = a.createFilterBar(list)
+	table := list.table
 
 	a.stylePipelineTable(table, proj)
 
@@ -29,23 +33,44 @@ func (a *App) createPipelinePage(proj config.GitLabProject) tview.Primitive {
 				a.pages.SwitchToPage(PageHome)
 				return nil
 			case 'r', 'R':
-				a.refreshPipelines(table, proj)
+				a.refreshPipelines(list)
+				return nil
+			case 't':
+				a.handlePipelineAction(list, "retry")
+				return nil
+			case 'x':
+				a.handlePipelineAction(list, "cancel")
+				return nil
+			case 'n':
+				a.handleTriggerPipeline(list)
+				return nil
+			case 'f':
+				a.toggleFilterBar(list)
 				return nil
 			}
 		case tcell.KeyEsc:
 			a.pages.SwitchToPage(PageHome)
 			return nil
 		case tcell.KeyEnter:
-			a.handlePipelineSelected(table, proj.ID)
+			a.handlePipelineSelected(table, proj)
 			return nil
+		case tcell.KeyDown, tcell.KeyPgDn, tcell.KeyEnd:
+			if row, _ := table.GetSelection(); row >= table.GetRowCount()-1 {
+				a.loadMorePipelines(list)
+			}
 		}
 		return event
 	})
 
 	container.
-		AddItem(header, 3, 0, false).
+		AddItem(header, pipelineHeaderHeight(filter), 0, false).
+		AddItem(list.filterBar, 0, 0, false).
 		AddItem(table, 0, 1, true)
 
+	a.poll(PagePipeline, func(done func(active bool)) {
+		a.pollPipelines(list, done)
+	})
+
 	return container
 }
 
@@ -58,21 +83,14 @@ func (a *App) stylePipelineTable(table *tview.Table, proj config.GitLabProject)
 	table.SetBackgroundColor(ColorBlue)
 }
 
-func (a *App) createPipelineHeader(proj config.GitLabProject) *tview.TextView {
+func (a *App) createPipelineHeader(proj config.GitLabProject, filter gitlab.PipelineFilter) *tview.TextView {
 	header := tview.NewTextView().
 		SetRegions(true).
 		SetTextAlign(tview.AlignCenter)
 
 	header.SetBackgroundColor(ColorBlue)
 
-	headerText := fmt.Sprintf(
-		"🔧 [::bu]%s[::-] - Pipeline Overview\n[::d]Projekt-ID: %d | Last updated: %s[::-]",
-		proj.Name,
-		proj.ID,
-		time.Now().Format("15:04:05"),
-	)
-
-	header.SetText(headerText)
+	header.SetText(pipelineHeaderText(proj, filter))
 
 	header.SetBorder(true)
 	header.SetBorderColor(ColorOrange)
@@ -83,7 +101,29 @@ func (a *App) createPipelineHeader(proj config.GitLabProject) *tview.TextView {
 	return header
 }
 
-func (a *App) handlePipelineSelected(table *tview.Table, projectID int) {
+func pipelineHeaderText(proj config.GitLabProject, filter gitlab.PipelineFilter) string {
+	text := fmt.Sprintf(
+		"🔧 [::bu]%s[::-] - Pipeline Overview\n[::d]Instanz: %s | Projekt-ID: %d | Last updated: %s[::-]",
+		proj.Name,
+		proj.Instance,
+		proj.ID,
+		time.Now().Format("15:04:05"),
+	)
+	if !filter.IsZero() {
+		text += fmt.Sprintf("\n🔍 Filter: %s [::d](f: ändern)[::-]", tview.Escape(describeFilter(filter)))
+	}
+	return text
+}
+
+// pipelineHeaderHeight makes room for the line with the active filter.
+func pipelineHeaderHeight(filter gitlab.PipelineFilter) int {
+	if filter.IsZero() {
+		return 4
+	}
+	return 5
+}
+
+func (a *App) handlePipelineSelected(table *tview.Table, proj config.GitLabProject) {
 	row, _ := table.GetSelection()
 	cell := table.GetCell(row, 0)
 	if cell == nil {
@@ -100,15 +140,39 @@ func (a *App) handlePipelineSelected(table *tview.Table, projectID int) {
 	switch v := ref.(type) {
 	case gitlab.Pipeline:
 		a.showNotification(fmt.Sprintf("Lade Jobs für Pipeline #%d...", v.ID), ColorSuccess)
-		page := a.createJobPage(projectID, v.ID)
-		a.pages.AddPage("JobPage", page, true, true)
-		a.pages.SwitchToPage("JobPage")
+		page := a.createJobPage(proj, v.ID)
+		a.pages.AddPage(PageJob, page, true, true)
+		a.pages.SwitchToPage(PageJob)
 	default:
 		a.showNotification("Unbekannter Pipeline-Typ", ColorDanger)
 	}
 }
 
-func (a *App) handlePipelineClick(projectID string) *tview.Table {
+// pipelinesPerPage is the number of pipelines fetched per lazy-load step.
+const pipelinesPerPage = 20
+
+// pipelineList is the state behind a pipeline table whose rows are loaded
+// page by page while the user scrolls down.
+type pipelineList struct {
+	table      *tview.Table
+	header     *tview.TextView
+	proj       config.GitLabProject
+	filter     gitlab.PipelineFilter
+	client     *gitlab.Client
+	iterator   *gitlab.Paginator[gitlab.Pipeline]
+	pipelines  []gitlab.Pipeline
+	loading    bool
+	active     bool
+	generation int
+
+	container     *tview.Flex
+	filterBar     *tview.Form
+	filterVisible bool
+}
+
+func (a *App) handlePipelineClick(proj config.GitLabProject, filter gitlab.PipelineFilter) *pipelineList {
+	client, _ := a.clientFor(proj)
+
 	table := tview.NewTable().
 		SetBorders(true).
 		SetSelectable(true, false)
@@ -117,56 +181,170 @@ func (a *App) handlePipelineClick(projectID string) *tview.Table {
 	table.SetBorderColor(ColorOrange)
 	table.SetBackgroundColor(ColorBlue)
 
-	loadingCell := tview.NewTableCell("⏳ Lade Pipelines...").
+	list := &pipelineList{table: table, proj: proj, filter: filter, client: client}
+
+	table.SetSelectionChangedFunc(func(row, column int) {
+		if row > 0 && row >= table.GetRowCount()-1 {
+			a.loadMorePipelines(list)
+		}
+	})
+
+	a.resetPipelineList(list, "⏳ Lade Pipelines...")
+	return list
+}
+
+func (a *App) refreshPipelines(list *pipelineList) {
+	a.resetPipelineList(list, "⏳ Aktualisiere Pipelines...")
+}
+
+// resetPipelineList drops all loaded rows and fetches the first page again.
+func (a *App) resetPipelineList(list *pipelineList, loadingText string) {
+	list.generation++
+	list.iterator = list.client.PipelineIterator(fmt.Sprint(list.proj.ID), pipelinesPerPage, list.filter)
+	list.pipelines = nil
+	list.loading = false
+
+	loadingCell := tview.NewTableCell(loadingText).
 		SetTextColor(tcell.ColorWhite).
 		SetSelectable(false)
-	table.SetCell(0, 0, loadingCell)
+
+	list.table.Clear()
+	list.table.SetCell(0, 0, loadingCell)
+
+	a.loadMorePipelines(list)
+}
+
+// loadMorePipelines appends the next page of pipelines to the table.
+func (a *App) loadMorePipelines(list *pipelineList) {
+	if list.loading || !list.iterator.HasNext() {
+		return
+	}
+	list.loading = true
+	generation := list.generation
+	iterator := list.iterator
+
+	if len(list.pipelines) > 0 {
+		list.table.SetCell(len(list.pipelines)+1, 0, tview.NewTableCell("⏳ Lade weitere Pipelines...").
+			SetTextColor(tcell.ColorWhite).
+			SetSelectable(false))
+	}
 
 	go func() {
-		pipelines, err := gitlab.GetAllPipelines(projectID, a.token, 5)
+		pipelines, err := iterator.Next()
 
 		a.app.QueueUpdateDraw(func() {
-			table.Clear()
+			if generation != list.generation {
+				return
+			}
+			list.loading = false
+			table := list.table
 
 			if err != nil {
 				errorCell := tview.NewTableCell("❌ Fehler beim Laden der Pipelines: " + err.Error()).
 					SetTextColor(ColorDanger).
 					SetSelectable(false)
-				table.SetCell(0, 0, errorCell)
+				if len(list.pipelines) == 0 {
+					table.Clear()
+				}
+				table.SetCell(min(len(list.pipelines)+1, table.GetRowCount()), 0, errorCell)
 				return
 			}
 
-			headerCell := tview.NewTableCell("🔧 Pipelines (" + fmt.Sprint(len(pipelines)) + ")").
-				SetTextColor(tcell.ColorWhite).
-				SetSelectable(false).
-				SetAttributes(tcell.AttrBold)
-			table.SetCell(0, 0, headerCell)
-
-			for i, p := range pipelines {
-				pipelineCell := a.createPipelineCell(projectID, p)
-				table.SetCell(i+1, 0, pipelineCell)
+			// Pipelines created since the first page was loaded shift the
+			// page boundaries, so skip the ones that are already shown.
+			seen := make(map[int]bool, len(list.pipelines))
+			for _, p := range list.pipelines {
+				seen[p.ID] = true
+			}
+			all := list.pipelines
+			for _, p := range pipelines {
+				if !seen[p.ID] {
+					all = append(all, p)
+				}
 			}
+			a.setPipelineRows(list, all)
+		})
+	}()
+}
 
-			if len(pipelines) > 0 {
-				table.Select(1, 0)
+// pollPipelines reloads the pipelines that are currently shown and updates
+// the rows in place.
+func (a *App) pollPipelines(list *pipelineList, done func(active bool)) {
+	if list.loading {
+		done(list.active)
+		return
+	}
+	generation := list.generation
+	limit := max(len(list.pipelines), pipelinesPerPage)
+
+	go func() {
+		pipelines, err := list.client.GetAllPipelines(fmt.Sprint(list.proj.ID), limit, list.filter)
+
+		a.app.QueueUpdateDraw(func() {
+			if err == nil && generation == list.generation && !list.loading {
+				a.setPipelineRows(list, pipelines)
 			}
+			done(list.active)
 		})
 	}()
+}
+
+// setPipelineRows replaces the table rows with pipelines and keeps the
+// selected pipeline selected.
+func (a *App) setPipelineRows(list *pipelineList, pipelines []gitlab.Pipeline) {
+	table := list.table
+
+	selectedID := 0
+	if row, _ := table.GetSelection(); row > 0 && row <= len(list.pipelines) {
+		selectedID = list.pipelines[row-1].ID
+	}
+
+	table.Clear()
+	list.pipelines = pipelines
+	list.active = false
 
-	return table
+	headerText := fmt.Sprintf("🔧 Pipelines (%d)", len(pipelines))
+	if list.iterator.HasNext() {
+		headerText = fmt.Sprintf("🔧 Pipelines (%d+)", len(pipelines))
+	}
+	headerCell := tview.NewTableCell(headerText).
+		SetTextColor(tcell.ColorWhite).
+		SetSelectable(false).
+		SetAttributes(tcell.AttrBold)
+	table.SetCell(0, 0, headerCell)
+
+	projectID := fmt.Sprint(list.proj.ID)
+	selectedRow := 1
+	for i, p := range pipelines {
+		table.SetCell(i+1, 0, a.createPipelineCell(list.client, projectID, p))
+		if p.ID == selectedID {
+			selectedRow = i + 1
+		}
+		if gitlab.IsActiveStatus(p.Status) {
+			list.active = true
+		}
+	}
+
+	if len(pipelines) > 0 {
+		table.Select(selectedRow, 0)
+	}
+	if list.header != nil {
+		list.header.SetText(pipelineHeaderText(list.proj, list.filter))
+	}
 }
 
-func (a *App) createPipelineCell(projectID string, pipeline gitlab.Pipeline) *tview.TableCell {
+func (a *App) createPipelineCell(client *gitlab.Client, projectID string, pipeline gitlab.Pipeline) *tview.TableCell {
 	statusEmoji := gitlab.StatusEmoji(pipeline.Status)
 
-	placeholderText := fmt.Sprintf("%s Pipeline : ⏳ Lade Commit...", statusEmoji)
-
-	if len(pipeline.Sha) >= 8 {
-		shortSha := pipeline.Sha[:8]
-		placeholderText += fmt.Sprintf(" (%s)", shortSha)
+	cellText := func(message string) string {
+		text := fmt.Sprintf("%s Pipeline : %s", statusEmoji, message)
+		if len(pipeline.Sha) >= 8 {
+			text += fmt.Sprintf(" (%s)", pipeline.Sha[:8])
+		}
+		return text
 	}
 
-	cell := tview.NewTableCell(placeholderText).
+	cell := tview.NewTableCell(cellText("⏳ Lade Commit...")).
 		SetReference(pipeline).
 		SetTextColor(tcell.ColorWhite).
 		SetSelectedStyle(tcell.StyleDefault.
@@ -174,8 +352,14 @@ func (a *App) createPipelineCell(projectID string, pipeline gitlab.Pipeline) *tv
 			Foreground(ColorPink).
 			Bold(true))
 
+	// Commit messages never change, so polling does not fetch them again.
+	if message, ok := a.commitMessages[pipeline.Sha]; ok {
+		cell.SetText(cellText(message))
+		return cell
+	}
+
 	go func(cell *tview.TableCell, sha string) {
-		commit, err := gitlab.GetCommit(projectID, sha, a.token)
+		commit, err := client.GetCommit(projectID, sha)
 		message := "Unknown commit message"
 		if err == nil {
 			message = commit.Message
@@ -183,56 +367,15 @@ func (a *App) createPipelineCell(projectID string, pipeline gitlab.Pipeline) *tv
 				message = message[:57] + "..."
 			}
 		}
+		message = strings.TrimSpace(message)
 
 		a.app.QueueUpdateDraw(func() {
-			newText := fmt.Sprintf("%s Pipeline : %s", statusEmoji, strings.TrimSpace(message))
-			if len(sha) >= 8 {
-				shortSha := sha[:8]
-				newText += fmt.Sprintf(" (%s)", shortSha)
+			if err == nil {
+				a.commitMessages[sha] = message
 			}
-			cell.SetText(newText)
+			cell.SetText(cellText(message))
 		})
 	}(cell, pipeline.Sha)
 
 	return cell
 }
-
-func (a *App) refreshPipelines(table *tview.Table, proj config.GitLabProject) {
-	loadingCell := tview.NewTableCell("⏳ Aktualisiere Pipelines...").
-		SetTextColor(tcell.ColorWhite).
-		SetSelectable(false)
-
-	table.Clear()
-	table.SetCell(0, 0, loadingCell)
-
-	go func() {
-		pipelines, err := gitlab.GetAllPipelines(fmt.Sprint(proj.ID), a.token, 5)
-
-		a.app.QueueUpdateDraw(func() {
-			table.Clear()
-
-			if err != nil {
-				errorCell := tview.NewTableCell("❌ Fehler beim Laden der Pipelines: " + err.Error()).
-					SetTextColor(ColorDanger).
-					SetSelectable(false)
-				table.SetCell(0, 0, errorCell)
-				return
-			}
-
-			headerCell := tview.NewTableCell("🔧 Pipelines (" + fmt.Sprint(len(pipelines)) + ")").
-				SetTextColor(tcell.ColorWhite).
-				SetSelectable(false).
-				SetAttributes(tcell.AttrBold)
-			table.SetCell(0, 0, headerCell)
-
-			for i, p := range pipelines {
-				pipelineCell := a.createPipelineCell(fmt.Sprint(proj.ID), p)
-				table.SetCell(i+1, 0, pipelineCell)
-			}
-
-			if len(pipelines) > 0 {
-				table.Select(1, 0)
-			}
-		})
-	}()
-}
@@ -0,0 +1,79 @@
+package ui
+
+import "time"
+
+type pollResult struct {
+	stop   bool
+	skip   bool
+	active bool
+}
+
+// poll refreshes the page registered under name in the background. The
+// interval is shortened while refresh reports active pipelines or jobs.
+// Polling pauses while the page is hidden and ends once the page is removed
+// or poll is called again for the same name. poll and refresh run on the UI
+// goroutine; refresh must call done exactly once.
+func (a *App) poll(name string, refresh func(done func(active bool))) {
+	if stop, ok := a.pollers[name]; ok {
+		close(stop)
+	}
+	stop := make(chan struct{})
+	a.pollers[name] = stop
+
+	go func() {
+		// Start with the short interval; the first refresh tells whether
+		// anything is actually running.
+		active := true
+		for {
+			interval := a.refreshInterval
+			if active {
+				interval = a.activeRefreshInterval
+			}
+
+			select {
+			case <-stop:
+				return
+			case <-time.After(interval):
+			}
+
+			result := make(chan pollResult, 1)
+			a.app.QueueUpdate(func() {
+				select {
+				case <-stop:
+					result <- pollResult{stop: true}
+					return
+				default:
+				}
+				if !a.pages.HasPage(name) {
+					delete(a.pollers, name)
+					result <- pollResult{stop: true}
+					return
+				}
+				if !a.isPageVisible(name) {
+					result <- pollResult{skip: true}
+					return
+				}
+				refresh(func(active bool) {
+					result <- pollResult{active: active}
+				})
+			})
+
+			r := <-result
+			switch {
+			case r.stop:
+				return
+			case !r.skip:
+				active = r.active
+			}
+		}
+	}()
+}
+
+func (a *App) isPageVisible(name string) bool {
+	for _, visible := range a.pages.GetPageNames(true) {
+		if visible == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+package ui
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/Youdontknowme720/Cimonv2/config"
+	"github.com/Youdontknowme720/Cimonv2/gitlab"
+	"github.com/Youdontknowme720/Cimonv2/gitrepo"
+)
+
+// openCurrentRepository jumps to the pipeline page of the GitLab project
+// the working directory is a checkout of, filtered to the current branch.
+// Nothing happens outside a git checkout or for remotes on hosts that are
+// not configured as an instance.
+func (a *App) openCurrentRepository() {
+	dir, err := os.Getwd()
+	if err != nil {
+		return
+	}
+	instances, clients := a.gitlabInstances, a.clients
+
+	go func() {
+		repo, err := gitrepo.Detect(dir)
+		if err != nil {
+			return
+		}
+		inst, path, ok := matchInstance(instances, repo)
+		if !ok {
+			return
+		}
+
+		client := clients[inst.Name]
+		project, err := client.GetProject(path)
+
+		a.app.QueueUpdateDraw(func() {
+			if err != nil {
+				a.showNotification(fmt.Sprintf("Git-Projekt %s nicht gefunden: %v", path, err), ColorWarning)
+				return
+			}
+			// Do not interrupt the user if they already opened something.
+			// Notifications, e.g. about the config, may cover the home page.
+			for _, name := range a.pages.GetPageNames(true) {
+				if name != PageHome && name != PageNotification {
+					return
+				}
+			}
+
+			proj := a.configuredProject(inst.Name, project)
+			page := a.createPipelinePage(proj, gitlab.PipelineFilter{Ref: repo.Branch})
+			a.pages.AddPage(PagePipeline, page, true, true)
+			a.pages.SwitchToPage(PagePipeline)
+		})
+	}()
+}
+
+// matchInstance finds the configured instance serving the repository's
+// remote and returns the project path relative to that instance.
+func matchInstance(instances []config.GitLabInstance, repo *gitrepo.Repo) (config.GitLabInstance, string, bool) {
+	for _, inst := range instances {
+		instanceURL := inst.URL
+		if instanceURL == "" {
+			instanceURL = gitlab.DefaultURL
+		}
+		u, err := url.Parse(instanceURL)
+		if err != nil || !strings.EqualFold(u.Hostname(), repo.Host) {
+			continue
+		}
+
+		// Instances served below a path, e.g. https://example.com/gitlab,
+		// have that prefix in http remotes but not in ssh remotes.
+		path := repo.Path
+		if prefix := strings.Trim(u.Path, "/"); prefix != "" {
+			path = strings.TrimPrefix(path, prefix+"/")
+		}
+		return inst, path, true
+	}
+	return config.GitLabInstance{}, "", false
+}
+
+// configuredProject returns the configured entry for project, or an ad-hoc
+// one named after its path if it is not in the config.
+func (a *App) configuredProject(instance string, project *gitlab.Project) config.GitLabProject {
+	for _, p := range a.gitlabProjects {
+		if p.Instance == instance && p.ID == project.ID {
+			return p
+		}
+	}
+	return config.GitLabProject{
+		ID:       project.ID,
+		Name:     project.PathWithNamespace,
+		Path:     project.PathWithNamespace,
+		Instance: instance,
+	}
+}
@@ -1,26 +1,137 @@
 package ui
 
 import (
+	"cmp"
+	"errors"
 	"fmt"
-	"strconv"
+	"strings"
+	"time"
 
 	"github.com/Youdontknowme720/Cimonv2/config"
+	"github.com/Youdontknowme720/Cimonv2/gitlab"
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 )
 
+// tokenSources are the choices of the token source drop-down with the
+// label of the field holding the token or its location.
+var tokenSources = []struct {
+	source, option, label, placeholder string
+}{
+	{config.TokenSourceConfig, "Token in config.yml", "Token", "glpat-..."},
+	{config.TokenSourceEnv, "Umgebungsvariable", "Variable", config.EnvToken},
+	{config.TokenSourceCommand, "Befehl", "Command", "pass show gitlab"},
+	{config.TokenSourceFile, "Datei (chmod 600)", "File", "~/.config/cimon/token"},
+}
+
 func (a *App) handleAddingToken() {
+	const newInstance = "+ Neue Instanz"
+
+	options := make([]string, 0, len(a.gitlabInstances)+1)
+	for _, inst := range a.gitlabInstances {
+		options = append(options, inst.Name)
+	}
+	options = append(options, newInstance)
+
+	sourceOptions := make([]string, len(tokenSources))
+	for i, ts := range tokenSources {
+		sourceOptions[i] = ts.option
+	}
+
 	form := tview.NewForm().
-		AddInputField("Token", "", 20, nil, nil)
+		AddDropDown("Instance", options, len(options)-1, nil).
+		AddInputField("Name", "", 30, nil, nil).
+		AddInputField("URL", gitlab.DefaultURL, 40, nil, nil).
+		AddDropDown("Token Source", sourceOptions, 0, nil).
+		AddInputField("Token", "", 40, nil, nil)
 	form.SetFieldTextColor(tcell.ColorBlack)
 	form.SetBorder(true).SetTitle("Editing ...").SetTitleAlign(tview.AlignCenter)
 	form.SetBorderColor(ColorOrange)
 
+	nameField := form.GetFormItemByLabel("Name").(*tview.InputField)
+	urlField := form.GetFormItemByLabel("URL").(*tview.InputField)
+	sourceDropDown := form.GetFormItemByLabel("Token Source").(*tview.DropDown)
+	tokenField := form.GetFormItemByLabel("Token").(*tview.InputField)
+
+	// The token field holds the token itself or, for the other sources,
+	// the variable name, command or file path.
+	sourceDropDown.SetSelectedFunc(func(option string, optionIndex int) {
+		ts := tokenSources[max(optionIndex, 0)]
+		tokenField.SetLabel(ts.label)
+		tokenField.SetPlaceholder(ts.placeholder)
+		if ts.source == config.TokenSourceConfig {
+			tokenField.SetMaskCharacter('*')
+		} else {
+			tokenField.SetMaskCharacter(0)
+		}
+	})
+	sourceDropDown.SetCurrentOption(0)
+
+	form.GetFormItemByLabel("Instance").(*tview.DropDown).
+		SetSelectedFunc(func(option string, optionIndex int) {
+			if optionIndex < 0 || optionIndex >= len(a.gitlabInstances) {
+				nameField.SetText("")
+				urlField.SetText(gitlab.DefaultURL)
+				sourceDropDown.SetCurrentOption(0)
+				tokenField.SetText("")
+				return
+			}
+			inst := a.gitlabInstances[optionIndex]
+			nameField.SetText(inst.Name)
+			urlField.SetText(inst.URL)
+			source := inst.TokenSource()
+			for i, ts := range tokenSources {
+				if ts.source == source {
+					sourceDropDown.SetCurrentOption(i)
+				}
+			}
+			switch source {
+			case config.TokenSourceEnv:
+				tokenField.SetText(inst.TokenEnv)
+			case config.TokenSourceCommand:
+				tokenField.SetText(inst.TokenCommand)
+			case config.TokenSourceFile:
+				tokenField.SetText(inst.TokenFile)
+			default:
+				tokenField.SetText(inst.Token)
+			}
+		})
+
 	saveFunc := func() {
-		newToken := form.GetFormItemByLabel("Token").(*tview.InputField).GetText()
-		config.AddNewToken(newToken)
-		a.token = newToken
-		a.pages.SwitchToPage(PageHome)
+		instanceURL := strings.TrimSpace(urlField.GetText())
+		name := strings.TrimSpace(nameField.GetText())
+		if name == "" {
+			name = config.InstanceNameFromURL(instanceURL)
+		}
+		inst := config.GitLabInstance{Name: name, URL: instanceURL}
+		value := strings.TrimSpace(tokenField.GetText())
+		idx, _ := sourceDropDown.GetCurrentOption()
+		ts := tokenSources[max(idx, 0)]
+		// Only the variable name has a default, an empty token, command or
+		// file would silently switch to another source.
+		if value == "" && ts.source != config.TokenSourceEnv {
+			a.showNotification(fmt.Sprintf("❌ %s darf nicht leer sein", ts.label), ColorDanger)
+			return
+		}
+		switch ts.source {
+		case config.TokenSourceEnv:
+			inst.TokenEnv = cmp.Or(value, config.EnvToken)
+		case config.TokenSourceCommand:
+			inst.TokenCommand = value
+		case config.TokenSourceFile:
+			inst.TokenFile = value
+		default:
+			inst.Token = value
+		}
+		if _, err := inst.ResolveToken(); err != nil {
+			a.showNotification("❌ "+err.Error(), ColorDanger)
+			return
+		}
+		if err := config.AddNewToken(inst); err != nil {
+			a.showNotification("❌ "+err.Error(), ColorDanger)
+			return
+		}
+		a.reloadHome()
 	}
 
 	abortFunc := func() {
@@ -45,10 +156,25 @@ func (a *App) handleAddingToken() {
 	a.pages.SwitchToPage(PageAddToken)
 }
 
+// projectSearchDelay is how long the project field waits after the last
+// keystroke before it searches the instance.
+const projectSearchDelay = 300 * time.Millisecond
+
 func (a *App) handleAddingProject() {
+	if len(a.gitlabInstances) == 0 {
+		a.showNotification("Bitte zuerst eine GitLab-Instanz mit Token anlegen", ColorWarning)
+		return
+	}
+
+	instanceNames := make([]string, len(a.gitlabInstances))
+	for i, inst := range a.gitlabInstances {
+		instanceNames[i] = inst.Name
+	}
+
 	form := tview.NewForm().
-		AddInputField("ProjectName", "", 0, nil, nil).
-		AddInputField("ProjectID", "", 0, nil, nil)
+		AddDropDown("Instance", instanceNames, 0, nil).
+		AddInputField("Project", "", 50, nil, nil).
+		AddInputField("Display Name", "", 30, nil, nil)
 
 	form.SetBorder(true).SetTitle("... Editing ...").SetTitleAlign(tview.AlignCenter)
 	form.SetFieldBackgroundColor(ColorOrange)
@@ -60,13 +186,92 @@ func (a *App) handleAddingProject() {
 	form.SetButtonBackgroundColor(ColorOrange)
 	form.SetButtonTextColor(tcell.ColorWhite)
 
+	instanceDropDown := form.GetFormItemByLabel("Instance").(*tview.DropDown)
+	projectField := form.GetFormItemByLabel("Project").(*tview.InputField)
+	projectField.SetPlaceholder("group/project, ID oder Suchbegriff")
+	nameField := form.GetFormItemByLabel("Display Name").(*tview.InputField)
+	nameField.SetPlaceholder("optional, sonst Projektpfad")
+
+	currentInstance := func() config.GitLabInstance {
+		idx, _ := instanceDropDown.GetCurrentOption()
+		if idx < 0 {
+			idx = 0
+		}
+		return a.gitlabInstances[idx]
+	}
+
+	// Search results of the last finished search, shown as autocomplete
+	// entries. searchSeq discards results of outdated searches.
+	var (
+		results   []gitlab.Project
+		timer     *time.Timer
+		searchSeq int
+		completed string
+	)
+
+	projectField.SetAutocompleteFunc(func(currentText string) []string {
+		needle := strings.ToLower(strings.TrimSpace(currentText))
+		if needle == "" || currentText == completed {
+			return nil
+		}
+		var entries []string
+		for _, p := range results {
+			if strings.Contains(strings.ToLower(p.PathWithNamespace), needle) ||
+				strings.Contains(strings.ToLower(p.Name), needle) {
+				entries = append(entries, p.PathWithNamespace)
+			}
+		}
+		return entries
+	})
+	projectField.SetAutocompletedFunc(func(text string, index, source int) bool {
+		if source == tview.AutocompletedNavigate {
+			return false
+		}
+		completed = text
+		projectField.SetText(text)
+		return true
+	})
+
+	search := func() {
+		if timer != nil {
+			timer.Stop()
+		}
+		searchSeq++
+		seq := searchSeq
+		query := normalizeProjectInput(projectField.GetText(), currentInstance().URL)
+		if len([]rune(query)) < 2 || query == completed {
+			results = nil
+			return
+		}
+		client := a.clients[currentInstance().Name]
+		timer = time.AfterFunc(projectSearchDelay, func() {
+			projects, err := client.SearchProjects(query, 20)
+
+			a.app.QueueUpdateDraw(func() {
+				if seq != searchSeq || err != nil {
+					return
+				}
+				results = projects
+				projectField.Autocomplete()
+			})
+		})
+	}
+	projectField.SetChangedFunc(func(string) { search() })
+	instanceDropDown.SetSelectedFunc(func(string, int) {
+		results = nil
+		search()
+	})
+
 	configOverviewTable := tview.NewTable()
-	_, activeProjects := config.GetProjectData()
-	for idx, project := range activeProjects {
+	for idx, project := range a.gitlabProjects {
 		configOverviewTable.SetCell(idx, 0,
 			tview.NewTableCell(fmt.Sprint(project.ID)).SetAlign(tview.AlignLeft).SetSelectable(false))
 		configOverviewTable.SetCell(idx, 1,
 			tview.NewTableCell(project.Name).SetAlign(tview.AlignLeft).SetSelectable(false))
+		configOverviewTable.SetCell(idx, 2,
+			tview.NewTableCell(project.Path).SetAlign(tview.AlignLeft).SetSelectable(false))
+		configOverviewTable.SetCell(idx, 3,
+			tview.NewTableCell(project.Instance).SetAlign(tview.AlignLeft).SetSelectable(false))
 	}
 	configOverviewTable.SetTitle("Current projects definied in config").
 		SetTitleAlign(tview.AlignCenter).
@@ -75,23 +280,58 @@ func (a *App) handleAddingProject() {
 		SetBorderColor(ColorOrange).
 		SetBackgroundColor(ColorBlue)
 
+	saving := false
 	saveFunc := func() {
-		name := form.GetFormItemByLabel("ProjectName").(*tview.InputField).GetText()
-		idStr := form.GetFormItemByLabel("ProjectID").(*tview.InputField).GetText()
-		id, err := strconv.Atoi(idStr)
-		if err != nil {
+		if saving {
 			return
 		}
-		config.AddNewProject(id, name)
-		a.pages.RemovePage(PageHome)
-		_, projects := config.GetProjectData()
-		a.gitlabProjects = projects
-		home := a.createHomeScreen(a.gitlabProjects)
-		a.pages.AddPage(PageHome, home, true, true)
-		a.pages.SwitchToPage(PageHome)
+		instance := currentInstance()
+		input := normalizeProjectInput(projectField.GetText(), instance.URL)
+		if input == "" {
+			a.showNotification("Bitte Projektpfad, ID oder Suchbegriff angeben", ColorWarning)
+			return
+		}
+		name := strings.TrimSpace(nameField.GetText())
+		client := a.clients[instance.Name]
+
+		saving = true
+		a.showNotification(fmt.Sprintf("Prüfe Projekt %s...", input), ColorSuccess)
+		go func() {
+			project, err := lookupProject(client, input)
+
+			a.app.QueueUpdateDraw(func() {
+				saving = false
+				if err != nil {
+					a.showNotification(projectLookupError(input, instance.Name, err), ColorDanger)
+					return
+				}
+				if a.isProjectConfigured(instance.Name, project.ID) {
+					a.showNotification(fmt.Sprintf("Projekt %s ist bereits konfiguriert",
+						project.PathWithNamespace), ColorWarning)
+					return
+				}
+				if name == "" {
+					name = project.PathWithNamespace
+				}
+				err := config.AddNewProject(config.GitLabProject{
+					ID:       project.ID,
+					Name:     name,
+					Path:     project.PathWithNamespace,
+					Instance: instance.Name,
+				})
+				if err != nil {
+					a.showNotification("❌ "+err.Error(), ColorDanger)
+					return
+				}
+				a.reloadHome()
+			})
+		}()
 	}
 
 	abortFunc := func() {
+		if timer != nil {
+			timer.Stop()
+		}
 		a.pages.SwitchToPage(PageHome)
 	}
 
@@ -116,3 +356,114 @@ func (a *App) handleAddingProject() {
 	a.pages.AddPage(PageAddProj, flex, true, true)
 	a.pages.SwitchToPage(PageAddProj)
 }
+
+// normalizeProjectInput accepts a project path, ID or the project's web URL
+// on the instance and returns the path or ID.
+func normalizeProjectInput(input, instanceURL string) string {
+	input = strings.TrimSpace(input)
+	if instanceURL != "" {
+		input = strings.TrimPrefix(input, strings.TrimSuffix(instanceURL, "/"))
+	}
+	input = strings.TrimSuffix(input, ".git")
+	return strings.Trim(input, "/")
+}
+
+// lookupProject finds the project for input, a path, an ID or a search
+// term. A search term is accepted if it matches exactly one project.
+func lookupProject(client *gitlab.Client, input string) (*gitlab.Project, error) {
+	project, err := client.GetProject(input)
+	if !gitlab.IsNotFound(err) {
+		return project, err
+	}
+
+	matches, searchErr := client.SearchProjects(input, 2)
+	switch {
+	case searchErr != nil || len(matches) == 0:
+		return nil, err
+	case len(matches) > 1:
+		return nil, fmt.Errorf("%q passt auf mehrere Projekte, bitte den Projektpfad angeben", input)
+	}
+	return &matches[0], nil
+}
+
+// projectLookupError turns a failed project lookup into a message for the
+// user. GitLab answers 404 both for missing projects and for projects the
+// token has no access to.
+func projectLookupError(input, instance string, err error) string {
+	switch {
+	case gitlab.IsNotFound(err):
+		return fmt.Sprintf("❌ Projekt %q auf %s nicht gefunden oder kein Zugriff mit diesem Token", input, instance)
+	case gitlab.IsUnauthorized(err):
+		return fmt.Sprintf("❌ Token für %s ist ungültig oder abgelaufen", instance)
+	}
+	return "❌ " + err.Error()
+}
+
+// reloadHome shows the home screen and reloads instances and projects from
+// the config. If the config cannot be read, the current projects are kept.
+func (a *App) reloadHome() {
+	// The reload below picks up whatever changed, including Cimon's own
+	// writes, so the watcher need not reload again.
+	a.configWritten()
+	a.pages.SwitchToPage(PageHome)
+	a.reloadConfig(func(err error) {
+		if err != nil {
+			a.showNotification("❌ "+err.Error(), ColorDanger)
+		}
+	})
+}
+
+// loadedConfig is a config read from disk together with the clients of
+// its instances.
+type loadedConfig struct {
+	stamp   fileStamp
+	cfg     config.Config
+	clients map[string]*gitlab.Client
+	// err holds validation and token errors.
+	err error
+}
+
+// loadConfig reads the config and resolves the tokens of its instances.
+// It does not touch the App, so it can run outside of the UI goroutine.
+func loadConfig() (loadedConfig, error) {
+	stamp, _ := statConfig()
+	cfg, err := config.ReadConfig()
+	var validationErr *config.ValidationError
+	if err != nil && !errors.As(err, &validationErr) {
+		return loadedConfig{}, err
+	}
+	clients, tokenErr := instanceClients(cfg.Instances)
+	return loadedConfig{stamp: stamp, cfg: cfg, clients: clients, err: errors.Join(err, tokenErr)}, nil
+}
+
+// reloadConfig loads the config in the background and applies it. done is
+// called on the UI goroutine with the validation and token errors, or with
+// the error that prevented the reload; then nothing is changed.
+func (a *App) reloadConfig(done func(err error)) {
+	go func() {
+		loaded, err := loadConfig()
+
+		a.app.QueueUpdateDraw(func() {
+			if err == nil {
+				err = a.applyConfig(loaded)
+			}
+			done(err)
+		})
+	}()
+}
+
+// applyConfig switches to a loaded config and rebuilds the home screen
+// without switching to it.
+func (a *App) applyConfig(loaded loadedConfig) error {
+	a.configStamp = loaded.stamp
+	a.gitlabInstances, a.clients = loaded.cfg.Instances, loaded.clients
+	a.gitlabProjects = loaded.cfg.Projects
+
+	// AddPage replaces the old home screen.
+	home := a.createHomeScreen(a.gitlabProjects)
+	a.pages.AddPage(PageHome, home, true, a.isPageVisible(PageHome))
+	if len(loaded.cfg.Notices) > 0 {
+		a.showNotification(strings.Join(loaded.cfg.Notices, "\n"), ColorSuccess)
+	}
+	return loaded.err
+}
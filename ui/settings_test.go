@@ -0,0 +1,61 @@
+package ui
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Youdontknowme720/Cimonv2/gitlab"
+)
+
+func TestLookupProject(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.EscapedPath() == "/api/v4/projects/group%2Fapi":
+			w.Write([]byte(`{"id":1,"path_with_namespace":"group/api"}`))
+		case r.URL.Path == "/api/v4/projects" && r.URL.Query().Get("search") == "frontend":
+			w.Write([]byte(`[{"id":2,"path_with_namespace":"group/frontend"}]`))
+		case r.URL.Path == "/api/v4/projects" && r.URL.Query().Get("search") == "service":
+			w.Write([]byte(`[{"id":3,"path_with_namespace":"a/service"},{"id":4,"path_with_namespace":"b/service"}]`))
+		case r.URL.Path == "/api/v4/projects":
+			w.Write([]byte(`[]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"404 Project Not Found"}`))
+		}
+	}))
+	defer srv.Close()
+	client := gitlab.NewClient(srv.URL, "token")
+
+	tests := []struct {
+		input    string
+		wantID   int
+		notFound bool
+		wantErr  string
+	}{
+		{input: "group/api", wantID: 1},
+		{input: "frontend", wantID: 2},
+		{input: "service", wantErr: "mehrere Projekte"},
+		{input: "missing", notFound: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			project, err := lookupProject(client, tt.input)
+			switch {
+			case tt.notFound:
+				if !gitlab.IsNotFound(err) {
+					t.Fatalf("err = %v, want not found", err)
+				}
+			case tt.wantErr != "":
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+			case err != nil:
+				t.Fatal(err)
+			case project.ID != tt.wantID:
+				t.Errorf("project = %d, want %d", project.ID, tt.wantID)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Youdontknowme720/Cimonv2/gitlab"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// handleTriggerPipeline shows a form to run a new pipeline for a ref with
+// custom variables and opens its job page once it was created.
+func (a *App) handleTriggerPipeline(list *pipelineList) {
+	proj := list.proj
+	projectID := fmt.Sprint(proj.ID)
+
+	form := tview.NewForm().
+		AddInputField("Ref", list.filter.Ref, 40, nil, nil).
+		AddTextArea("Variables", "", 40, 6, 0, nil)
+
+	form.SetBorder(true).
+		SetTitle(fmt.Sprintf(" ▶️ Neue Pipeline für %s ", proj.Name)).
+		SetTitleAlign(tview.AlignCenter)
+	form.SetFieldBackgroundColor(ColorOrange)
+	form.SetFieldTextColor(tcell.ColorBlack)
+	form.SetLabelColor(tcell.ColorWhite)
+	form.SetTitleColor(ColorPink)
+	form.SetBorderColor(ColorOrange)
+	form.SetBackgroundColor(ColorBlue)
+	form.SetButtonBackgroundColor(ColorOrange)
+	form.SetButtonTextColor(tcell.ColorWhite)
+
+	refField := form.GetFormItemByLabel("Ref").(*tview.InputField)
+	refField.SetPlaceholder("⏳ Lade Branches...")
+	variablesField := form.GetFormItemByLabel("Variables").(*tview.TextArea)
+	variablesField.SetPlaceholder("KEY=VALUE, eine Variable pro Zeile")
+
+	var branches []string
+	refField.SetAutocompleteFunc(func(currentText string) []string {
+		var entries []string
+		needle := strings.ToLower(currentText)
+		for _, branch := range branches {
+			if strings.Contains(strings.ToLower(branch), needle) {
+				entries = append(entries, branch)
+			}
+		}
+		return entries
+	})
+
+	go func() {
+		project, projectErr := list.client.GetProject(projectID)
+		branchList, branchErr := list.client.GetBranches(projectID)
+
+		a.app.QueueUpdateDraw(func() {
+			refField.SetPlaceholder("Branch oder Tag")
+			if projectErr == nil && refField.GetText() == "" {
+				refField.SetText(project.DefaultBranch)
+			}
+			if branchErr == nil {
+				branches = branches[:0]
+				for _, b := range branchList {
+					branches = append(branches, b.Name)
+				}
+			}
+		})
+	}()
+
+	abortFunc := func() {
+		a.pages.RemovePage(PageTrigger)
+		a.pages.SwitchToPage(PagePipeline)
+	}
+
+	runFunc := func() {
+		ref := strings.TrimSpace(refField.GetText())
+		if ref == "" {
+			a.showNotification("Bitte einen Ref angeben", ColorWarning)
+			return
+		}
+		variables, err := parseVariables(variablesField.GetText())
+		if err != nil {
+			a.showNotification(err.Error(), ColorDanger)
+			return
+		}
+
+		a.showNotification(fmt.Sprintf("Starte Pipeline für %s...", ref), ColorSuccess)
+		go func() {
+			pipeline, err := list.client.CreatePipeline(projectID, ref, variables)
+
+			a.app.QueueUpdateDraw(func() {
+				if err != nil {
+					a.showNotification("❌ "+err.Error(), ColorDanger)
+					return
+				}
+				a.pages.RemovePage(PageTrigger)
+				a.refreshPipelines(list)
+				page := a.createJobPage(proj, pipeline.ID)
+				a.pages.AddPage(PageJob, page, true, true)
+				a.pages.SwitchToPage(PageJob)
+			})
+		}()
+	}
+
+	form.AddButton("Run", runFunc)
+	form.AddButton("Abort", abortFunc)
+
+	form.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyCtrlS:
+			runFunc()
+			return nil
+		case event.Key() == tcell.KeyCtrlB || event.Key() == tcell.KeyEsc:
+			abortFunc()
+			return nil
+		}
+		return event
+	})
+
+	a.pages.AddPage(PageTrigger, form, true, true)
+	a.pages.SwitchToPage(PageTrigger)
+}
+
+// parseVariables parses KEY=VALUE lines. Empty lines and lines starting
+// with # are ignored.
+func parseVariables(text string) ([]gitlab.PipelineVariable, error) {
+	var variables []gitlab.PipelineVariable
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("Zeile %d: erwartet KEY=VALUE, gefunden %q", i+1, line)
+		}
+		variables = append(variables, gitlab.PipelineVariable{Key: key, Value: value})
+	}
+	return variables, nil
+}
@@ -0,0 +1,61 @@
+package ui
+
+import (
+	"os"
+	"time"
+
+	"github.com/Youdontknowme720/Cimonv2/config"
+)
+
+// configWatchInterval is how often the config file is checked for changes.
+const configWatchInterval = 2 * time.Second
+
+// fileStamp identifies a version of a file by its modification time and
+// size.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+func statConfig() (fileStamp, bool) {
+	info, err := os.Stat(config.Path())
+	if err != nil {
+		return fileStamp{}, false
+	}
+	return fileStamp{info.ModTime(), info.Size()}, true
+}
+
+// configWritten records the current config file as loaded after Cimon
+// wrote it, so watchConfig does not reload it as an outside change.
+func (a *App) configWritten() {
+	a.configStamp, _ = statConfig()
+}
+
+// watchConfig polls the config file and reloads instances and projects
+// when it was changed by another program. Changes made by Cimon itself are
+// already loaded and skipped.
+func (a *App) watchConfig() {
+	last := a.configStamp
+	go func() {
+		for range time.Tick(configWatchInterval) {
+			stamp, ok := statConfig()
+			// A missing file is usually an editor replacing it.
+			if !ok || stamp == last {
+				continue
+			}
+			last = stamp
+
+			a.app.QueueUpdateDraw(func() {
+				if stamp == a.configStamp {
+					return
+				}
+				a.reloadConfig(func(err error) {
+					if err != nil {
+						a.configStamp = stamp
+						a.showNotification("❌ config.yml konnte nicht neu geladen werden:\n"+err.Error(), ColorDanger)
+					}
+				})
+			})
+		}
+	}()
+}